@@ -0,0 +1,37 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+)
+
+// main starts a broker that controllers can hand their turns to with -broker.
+func main() {
+	port := flag.String(
+		"port",
+		"8030",
+		"Specify the port to listen for controllers on. Defaults to 8030.")
+
+	servers := flag.String(
+		"servers",
+		"127.0.0.1:8040",
+		"Specify the comma-separated addresses of the worker servers. Defaults to 127.0.0.1:8040.")
+
+	flag.Parse()
+
+	listener, err := net.Listen("tcp", ":"+*port)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	fmt.Println("Broker listening on", listener.Addr())
+	if err = gol.ServeBroker(listener, strings.Split(*servers, ",")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+)
+
+// TestCheckpoint tests checkpointing a run and resuming it with the right turn numbers.
+func TestCheckpoint(t *testing.T) {
+	t.Run("resume", testCheckpointResume)
+	t.Run("periodic", testCheckpointPeriodic)
+}
+
+func testCheckpointResume(t *testing.T) {
+	checkpoint := filepath.Join(t.TempDir(), "run.checkpoint")
+	first := gol.Params{Turns: 40, Threads: 4, ImageWidth: 64, ImageHeight: 64, Checkpoint: checkpoint, OutDir: t.TempDir()}
+	runFinal(first)
+
+	resumed := gol.Params{Turns: 100, Threads: 3, Resume: checkpoint, OutDir: t.TempDir()}
+	events := make(chan gol.Event)
+	go gol.Run(resumed, events, nil)
+
+	var final gol.FinalTurnComplete
+	saved := ""
+	for event := range events {
+		switch e := event.(type) {
+		case gol.CellFlipped:
+			assert(t, e.CompletedTurns == 40, "The resumed cells should be flipped at turn 40, not %v", e.CompletedTurns)
+		case gol.TurnComplete:
+			assert(t, e.CompletedTurns > 40, "A resumed run should only complete turns after 40, not %v", e.CompletedTurns)
+		case gol.FinalTurnComplete:
+			final = e
+		case gol.ImageOutputComplete:
+			saved = e.Filename
+		}
+	}
+	assert(t, final.CompletedTurns == 100, "The resumed run should finish at turn 100, not %v", final.CompletedTurns)
+	assert(t, saved == "64x64x100", "The final image should be 64x64x100, not %v", saved)
+	resumed.ImageWidth, resumed.ImageHeight = 64, 64
+	assertEqualBoard(t, final.Alive, readAliveCells("check/images/64x64x100.pgm", 64, 64), resumed)
+}
+
+func testCheckpointPeriodic(t *testing.T) {
+	checkpoint := filepath.Join(t.TempDir(), "run.checkpoint")
+	p := gol.Params{
+		Turns:           100000000,
+		Threads:         4,
+		ImageWidth:      512,
+		ImageHeight:     512,
+		Checkpoint:      checkpoint,
+		CheckpointEvery: 100 * time.Millisecond,
+		OutDir:          t.TempDir(),
+	}
+	events := make(chan gol.Event, 1000)
+	keyPresses := make(chan rune, 10)
+	go gol.Run(p, events, keyPresses)
+
+	periodic := 0
+	quitAt := 0
+	var final gol.FinalTurnComplete
+	for event := range events {
+		switch e := event.(type) {
+		case gol.CheckpointComplete:
+			if periodic++; periodic == 3 {
+				keyPresses <- 'q'
+			}
+			quitAt = e.CompletedTurns
+		case gol.FinalTurnComplete:
+			final = e
+		}
+	}
+	assert(t, periodic >= 4, "Expected periodic checkpoints and one on quitting, got %v", periodic)
+	assert(t, quitAt == final.CompletedTurns, "The last checkpoint should be at the final turn %v, not %v", final.CompletedTurns, quitAt)
+
+	// Resuming with no turns left to run reproduces the world that was quit.
+	resumed := gol.Params{Turns: 0, Threads: 1, Resume: checkpoint, OutDir: t.TempDir()}
+	assertEqualBoard(t, runFinal(resumed), final.Alive, p)
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/rpc"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/stubs"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// TestCluster tests running the strips on worker servers that exchange halo rows with each other in a ring.
+func TestCluster(t *testing.T) {
+	t.Run("images", testClusterImages)
+	t.Run("topology", testClusterTopology)
+	t.Run("processes", testClusterProcesses)
+}
+
+// startWorkers starts worker servers in this process and returns their addresses.
+func startWorkers(t *testing.T, n int) []string {
+	var addresses []string
+	for i := 0; i < n; i++ {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		util.Check(err)
+		addresses = append(addresses, listener.Addr().String())
+		go gol.ServeWorker(listener)
+	}
+	t.Cleanup(func() { shutdownWorkers(addresses) })
+	return addresses
+}
+
+func shutdownWorkers(addresses []string) {
+	for _, address := range addresses {
+		if client, err := rpc.Dial("tcp", address); err == nil {
+			_ = client.Call(stubs.WorkerShutdown, stubs.Empty{}, new(stubs.Empty))
+			client.Close()
+		}
+	}
+}
+
+func testClusterImages(t *testing.T) {
+	for _, n := range []int{1, 2, 5} {
+		workers := startWorkers(t, n)
+		for _, size := range []int{16, 64} {
+			for _, turns := range []int{0, 1, 100} {
+				p := gol.Params{Turns: turns, ImageWidth: size, ImageHeight: size, Workers: workers, OutDir: t.TempDir()}
+				t.Run(fmt.Sprintf("%vx%vx%v-%v", size, size, turns, n), func(t *testing.T) {
+					expectedAlive := readAliveCells(fmt.Sprintf("check/images/%vx%vx%v.pgm", size, size, turns), size, size)
+					assertEqualBoard(t, runFinal(p), expectedAlive, p)
+				})
+			}
+		}
+	}
+}
+
+func testClusterTopology(t *testing.T) {
+	workers := startWorkers(t, 3)
+	for _, topology := range []gol.Topology{gol.Bounded, gol.Cylinder, gol.KleinBottle} {
+		p := gol.Params{Turns: 100, ImageWidth: 64, ImageHeight: 64, Topology: topology, Workers: workers, OutDir: t.TempDir()}
+		t.Run(topology.String(), func(t *testing.T) {
+			expectedAlive := referenceRun(readAliveCells("images/64x64.pgm", 64, 64), p)
+			assertEqualBoard(t, runFinal(p), expectedAlive, p)
+		})
+	}
+}
+
+// testClusterProcesses runs the 512x512 image on three server processes.
+func testClusterProcesses(t *testing.T) {
+	server := filepath.Join(t.TempDir(), "server")
+	if output, err := exec.Command("go", "build", "-o", server, "./server").CombinedOutput(); err != nil {
+		t.Fatalf("Building the server failed: %v\n%s", err, output)
+	}
+
+	var workers []string
+	var processes []*exec.Cmd
+	for i := 0; i < 3; i++ {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		util.Check(err)
+		port := listener.Addr().(*net.TCPAddr).Port
+		listener.Close()
+
+		cmd := exec.Command(server, "-port", fmt.Sprint(port))
+		util.Check(cmd.Start())
+		processes = append(processes, cmd)
+		workers = append(workers, fmt.Sprintf("127.0.0.1:%v", port))
+	}
+	defer func() {
+		for _, cmd := range processes {
+			_ = cmd.Process.Kill()
+		}
+	}()
+	for _, address := range workers {
+		for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(50 * time.Millisecond) {
+			conn, err := net.Dial("tcp", address)
+			if err == nil {
+				conn.Close()
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("Server %v did not start: %v", address, err)
+			}
+		}
+	}
+
+	p := gol.Params{Turns: 100, ImageWidth: 512, ImageHeight: 512, Workers: workers, OutDir: t.TempDir()}
+	expectedAlive := readAliveCells("check/images/512x512x100.pgm", 512, 512)
+	assertEqualBoard(t, runFinal(p), expectedAlive, p)
+
+	shutdownWorkers(workers)
+	for _, cmd := range processes {
+		exited := make(chan error, 1)
+		go func(cmd *exec.Cmd) { exited <- cmd.Wait() }(cmd)
+		select {
+		case err := <-exited:
+			assert(t, err == nil, "The server should exit cleanly, not with %v", err)
+		case <-time.After(5 * time.Second):
+			t.Error("The server should exit after being shut down")
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+)
+
+// TestCycles tests detecting the world repeating and skipping whole periods of the cycle.
+func TestCycles(t *testing.T) {
+	t.Run("patterns", testCyclePatterns)
+	t.Run("skip", testCycleSkip)
+}
+
+// runCycles runs p and returns the cycles it detected along with its final turn.
+func runCycles(p gol.Params) ([]gol.CycleDetected, gol.FinalTurnComplete) {
+	events := make(chan gol.Event)
+	go gol.Run(p, events, nil)
+	var cycles []gol.CycleDetected
+	var final gol.FinalTurnComplete
+	for event := range events {
+		switch e := event.(type) {
+		case gol.CycleDetected:
+			cycles = append(cycles, e)
+		case gol.FinalTurnComplete:
+			final = e
+		}
+	}
+	return cycles, final
+}
+
+func testCyclePatterns(t *testing.T) {
+	tests := []struct {
+		name, pattern string
+		start, period int
+	}{
+		{"blinker", "OOO\n", 0, 2},
+		{"tromino", "O\nOO\n", 1, 1},
+		{"glider", ".O\n..O\nOOO\n", 0, 64},
+		{"dead", "O\n", 1, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			input := writePattern(t, test.name+".cells", test.pattern)
+			p := gol.Params{Turns: 200, Threads: 4, ImageWidth: 16, ImageHeight: 16, Input: input, OffsetX: 5, OffsetY: 5, DetectCycles: true}
+			cycles, _ := runCycles(p)
+			assert(t, len(cycles) == 1, "Expected one cycle to be detected, got %v", len(cycles))
+			if len(cycles) == 1 {
+				e := cycles[0]
+				assert(t, e.Start == test.start && e.Period == test.period,
+					"Expected a cycle of period %v from turn %v, got period %v from turn %v", test.period, test.start, e.Period, e.Start)
+				assert(t, e.CompletedTurns >= e.Start+e.Period, "The cycle cannot be detected at turn %v, before it has repeated", e.CompletedTurns)
+			}
+		})
+	}
+}
+
+// testCycleSkip runs the 512x512 image for ten billion turns, which it settles into a cycle of period 2 long before.
+func testCycleSkip(t *testing.T) {
+	p := gol.Params{Turns: 10000000000, Threads: 8, ImageWidth: 512, ImageHeight: 512, SkipCycles: true, OutDir: t.TempDir()}
+	cycles, final := runCycles(p)
+	assert(t, len(cycles) == 1 && cycles[0].Period == 2, "Expected the 512x512 image to fall into a cycle of period 2, got %v", cycles)
+	assert(t, final.CompletedTurns == p.Turns, "FinalTurnComplete should be at turn %v, not %v", p.Turns, final.CompletedTurns)
+
+	// An even number of turns into the cycle, the world is as it is at turn 10000.
+	reference := gol.Params{Turns: 10000, ImageWidth: 512, ImageHeight: 512, Engine: "hashlife", OutDir: t.TempDir()}
+	assertEqualBoard(t, final.Alive, runFinal(reference), p)
+}
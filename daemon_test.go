@@ -0,0 +1,197 @@
+package main
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// TestDaemon tests detaching a controller with 'q', attaching another mid-run, and stopping the daemon with 'k'.
+func TestDaemon(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "gol.sock")
+	listener, err := net.Listen("unix", socket)
+	util.Check(err)
+	p := gol.Params{Turns: 100000000, Threads: 4, ImageWidth: 64, ImageHeight: 64, OutDir: t.TempDir()}
+	served := make(chan error, 1)
+	go func() { served <- gol.ServeDaemon(listener, p, nil) }()
+
+	// The first controller sees the simulation running and detaches.
+	events := make(chan gol.Event, 1000)
+	keyPresses := make(chan rune, 10)
+	attached, err := gol.Attach(socket, events, keyPresses, nil)
+	util.Check(err)
+	assert(t, attached.ImageWidth == 64 && attached.ImageHeight == 64, "The daemon should send its params")
+	turns := 0
+	for event := range events {
+		if e, ok := event.(gol.TurnComplete); ok && turns == 0 && e.CompletedTurns > 0 {
+			turns = e.CompletedTurns
+			keyPresses <- 'q'
+		}
+		if _, ok := event.(gol.FinalTurnComplete); ok {
+			t.Error("Detaching with 'q' should not stop the simulation")
+		}
+	}
+	assert(t, turns > 0, "The first controller should see turns complete")
+
+	// The second controller rebuilds the board from CellFlipped events and follows it to the end.
+	time.Sleep(200 * time.Millisecond)
+	events = make(chan gol.Event, 1000)
+	keyPresses = make(chan rune, 10)
+	_, err = gol.Attach(socket, events, keyPresses, nil)
+	util.Check(err)
+
+	board := make(map[util.Cell]bool)
+	flip := func(cell util.Cell) {
+		board[cell] = !board[cell]
+		if !board[cell] {
+			delete(board, cell)
+		}
+	}
+	attachedAt := -1
+	var final gol.FinalTurnComplete
+	for event := range events {
+		switch e := event.(type) {
+		case gol.CellFlipped:
+			flip(e.Cell)
+		case gol.CellsFlipped:
+			for _, cell := range e.Cells {
+				flip(cell)
+			}
+		case gol.TurnComplete:
+			if attachedAt < 0 {
+				attachedAt = e.CompletedTurns
+				keyPresses <- 'k'
+			}
+		case gol.FinalTurnComplete:
+			final = e
+		}
+	}
+	assert(t, attachedAt > turns, "The second controller should attach at a later turn than %v, not %v", turns, attachedAt)
+	var cells []util.Cell
+	for cell := range board {
+		cells = append(cells, cell)
+	}
+	assertEqualBoard(t, cells, final.Alive, p)
+
+	select {
+	case err := <-served:
+		assert(t, err == nil, "The daemon should stop cleanly, not with %v", err)
+	case <-time.After(5 * time.Second):
+		t.Error("The daemon should stop after 'k'")
+	}
+}
+
+// TestDaemonBacklog tests that controllers which read slowly or not at all never hold up the daemon.
+func TestDaemonBacklog(t *testing.T) {
+	t.Run("stalled", testDaemonStalled)
+	t.Run("slow", testDaemonSlow)
+}
+
+// serveDaemon starts a daemon running an unlimited number of turns and returns its socket.
+func serveDaemon(t *testing.T, p gol.Params) (string, <-chan error) {
+	socket := filepath.Join(t.TempDir(), "gol.sock")
+	listener, err := net.Listen("unix", socket)
+	util.Check(err)
+	served := make(chan error, 1)
+	go func() { served <- gol.ServeDaemon(listener, p, nil) }()
+	return socket, served
+}
+
+// awaitServed waits for a daemon to stop after 'k'.
+func awaitServed(t *testing.T, served <-chan error) {
+	select {
+	case err := <-served:
+		assert(t, err == nil, "The daemon should stop cleanly, not with %v", err)
+	case <-time.After(5 * time.Second):
+		t.Error("The daemon should stop after 'k'")
+	}
+}
+
+// testDaemonStalled connects a controller that never reads, so its socket fills up while the turns run.
+func testDaemonStalled(t *testing.T) {
+	p := gol.Params{Turns: 100000000, Threads: 4, ImageWidth: 64, ImageHeight: 64, OutDir: t.TempDir()}
+	socket, served := serveDaemon(t, p)
+	stalled, err := net.Dial("unix", socket)
+	util.Check(err)
+	defer stalled.Close()
+	time.Sleep(time.Second)
+
+	events := make(chan gol.Event, 1000)
+	keyPresses := make(chan rune, 10)
+	attachedErr := make(chan error, 1)
+	go func() {
+		_, err := gol.Attach(socket, events, keyPresses, nil)
+		attachedErr <- err
+	}()
+	select {
+	case err := <-attachedErr:
+		util.Check(err)
+	case <-time.After(3 * time.Second):
+		t.Fatal("Attaching should not wait for a controller that has stopped reading")
+	}
+
+	attachedAt := -1
+	for event := range events {
+		if e, ok := event.(gol.TurnComplete); ok && attachedAt < 0 {
+			attachedAt = e.CompletedTurns
+			keyPresses <- 'k'
+		}
+	}
+	assert(t, attachedAt > 1000, "The simulation should carry on while a controller has stopped reading, but only reached turn %v", attachedAt)
+	awaitServed(t, served)
+}
+
+// testDaemonSlow reads events more slowly than the simulation sends them. Turns are skipped, but the board
+// the controller follows still ends up as the final world.
+func testDaemonSlow(t *testing.T) {
+	p := gol.Params{Turns: 100000000, Threads: 4, ImageWidth: 64, ImageHeight: 64, OutDir: t.TempDir()}
+	socket, served := serveDaemon(t, p)
+	events := make(chan gol.Event)
+	keyPresses := make(chan rune, 10)
+	_, err := gol.Attach(socket, events, keyPresses, nil)
+	util.Check(err)
+
+	board := make(map[util.Cell]bool)
+	flip := func(cell util.Cell) {
+		board[cell] = !board[cell]
+		if !board[cell] {
+			delete(board, cell)
+		}
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	killed := false
+	lastTurn, skipped := 0, false
+	var final gol.FinalTurnComplete
+	for event := range events {
+		switch e := event.(type) {
+		case gol.CellFlipped:
+			flip(e.Cell)
+		case gol.CellsFlipped:
+			for _, cell := range e.Cells {
+				flip(cell)
+			}
+		case gol.TurnComplete:
+			skipped = skipped || e.CompletedTurns > lastTurn+1
+			lastTurn = e.CompletedTurns
+			if time.Now().Before(deadline) {
+				time.Sleep(time.Millisecond)
+			} else if !killed {
+				keyPresses <- 'k'
+				killed = true
+			}
+		case gol.FinalTurnComplete:
+			final = e
+		}
+	}
+	assert(t, skipped, "A slow controller should skip turns")
+	var cells []util.Cell
+	for cell := range board {
+		cells = append(cells, cell)
+	}
+	assertEqualBoard(t, cells, final.Alive, p)
+	awaitServed(t, served)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/rpc"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/stubs"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// TestDistributed tests running the turns on a broker and worker servers over net/rpc on localhost.
+func TestDistributed(t *testing.T) {
+	t.Run("topology", testDistributedTopology)
+	t.Run("k", testDistributedK)
+}
+
+// startDistributed starts a broker with the given number of worker servers.
+// It returns the broker's address and a channel that is closed once the broker and every server have shut down.
+func startDistributed(t *testing.T, servers int) (string, <-chan struct{}) {
+	listen := func() net.Listener {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		util.Check(err)
+		return listener
+	}
+
+	stopped := make(chan error, servers+1)
+	var addresses []string
+	for i := 0; i < servers; i++ {
+		listener := listen()
+		addresses = append(addresses, listener.Addr().String())
+		go func() { stopped <- gol.ServeWorker(listener) }()
+	}
+	listener := listen()
+	go func() { stopped <- gol.ServeBroker(listener, addresses) }()
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i <= servers; i++ {
+			if err := <-stopped; err != nil {
+				t.Errorf("Shutting down returned %v", err)
+			}
+		}
+		close(done)
+	}()
+	return listener.Addr().String(), done
+}
+
+func testDistributedTopology(t *testing.T) {
+	broker, done := startDistributed(t, 3)
+	for _, topology := range []gol.Topology{gol.Torus, gol.Bounded, gol.CrossSurface} {
+		t.Run(topology.String(), func(t *testing.T) {
+			p := gol.Params{Turns: 100, Threads: 1, ImageWidth: 64, ImageHeight: 64, Topology: topology, Broker: broker}
+			expectedAlive := referenceRun(readAliveCells("images/64x64.pgm", 64, 64), p)
+			assertEqualBoard(t, runFinal(p), expectedAlive, p)
+		})
+	}
+
+	client, err := rpc.Dial("tcp", broker)
+	util.Check(err)
+	util.Check(client.Call(stubs.BrokerShutdown, stubs.Empty{}, new(stubs.Empty)))
+	client.Close()
+	<-done
+}
+
+func testDistributedK(t *testing.T) {
+	broker, done := startDistributed(t, 2)
+	p := gol.Params{Turns: 100000000, Threads: 1, ImageWidth: 512, ImageHeight: 512, Broker: broker, OutDir: t.TempDir()}
+	events := make(chan gol.Event, 1000)
+	keyPresses := make(chan rune, 10)
+	go gol.Run(p, events, keyPresses)
+
+	time.Sleep(500 * time.Millisecond)
+	keyPresses <- 'k'
+
+	var final gol.FinalTurnComplete
+	saved := ""
+	for event := range events {
+		switch e := event.(type) {
+		case gol.FinalTurnComplete:
+			final = e
+		case gol.ImageOutputComplete:
+			saved = e.Filename
+		case gol.ErrorEvent:
+			t.Errorf("Unexpected %v", e)
+		}
+	}
+	assert(t, final.CompletedTurns > 0, "Some turns should be completed before 'k'")
+	assert(t, saved == fmt.Sprintf("512x512x%v", final.CompletedTurns), "The final turn should be saved, not %q", saved)
+	savedAlive := readAliveCells(filepath.Join(p.OutDir, saved+".pgm"), 512, 512)
+	assertEqualBoard(t, savedAlive, final.Alive, p)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Error("The broker and servers should shut down after 'k'")
+	}
+}
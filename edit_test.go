@@ -0,0 +1,136 @@
+package main
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// TestEdit tests editing cells between turns, locally and through a daemon.
+func TestEdit(t *testing.T) {
+	t.Run("local", testEditLocal)
+	t.Run("daemon", testEditDaemon)
+}
+
+// awaitFlipped waits for the next CellsFlipped event and the TurnComplete after it.
+func awaitFlipped(t *testing.T, events <-chan gol.Event) (gol.CellsFlipped, int) {
+	var flipped gol.CellsFlipped
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				t.Fatal("Events closed before the cells flipped")
+			}
+			switch e := event.(type) {
+			case gol.CellsFlipped:
+				flipped = e
+			case gol.TurnComplete:
+				if flipped.Cells != nil {
+					return flipped, e.CompletedTurns
+				}
+			}
+		case <-timeout:
+			t.Fatal("No cells flipped in 5 seconds")
+		}
+	}
+}
+
+// awaitPaused waits for the StateChange to Paused and returns its turn.
+func awaitPaused(t *testing.T, events <-chan gol.Event) int {
+	for event := range events {
+		if e, ok := event.(gol.StateChange); ok && e.NewState == gol.Paused {
+			return e.CompletedTurns
+		}
+	}
+	t.Fatal("Events closed before the run paused")
+	return 0
+}
+
+func testEditLocal(t *testing.T) {
+	empty := writePattern(t, "empty.cells", ".\n")
+	p := gol.Params{Turns: 1000, Threads: 4, ImageWidth: 16, ImageHeight: 16, Input: empty, OutDir: t.TempDir()}
+	keyPresses := make(chan rune, 10)
+	edits := make(chan gol.CellEdit, 10)
+	events := make(chan gol.Event, 1000)
+	keyPresses <- 'p'
+	go gol.RunEdits(p, events, keyPresses, edits)
+	pausedAt := awaitPaused(t, events)
+
+	// Drawing a blinker flips its cells at the paused turn; drawing over a live cell changes nothing.
+	blinker := []util.Cell{{X: 5, Y: 5}, {X: 6, Y: 5}, {X: 7, Y: 5}}
+	for _, cell := range blinker {
+		edits <- gol.CellEdit{Cell: cell, Alive: true}
+	}
+	drawn := map[util.Cell]bool{}
+	for len(drawn) < len(blinker) {
+		flipped, turn := awaitFlipped(t, events)
+		assert(t, turn == pausedAt, "Edits should not change the turn from %v, but it is %v", pausedAt, turn)
+		for _, cell := range flipped.Cells {
+			drawn[cell] = true
+		}
+	}
+	assertEqualBoard(t, keys(drawn), blinker, p)
+
+	edits <- gol.CellEdit{Cell: util.Cell{X: 6, Y: 5}, Alive: true}
+	edits <- gol.CellEdit{Cell: util.Cell{X: 7, Y: 5}, Alive: false}
+	flipped, _ := awaitFlipped(t, events)
+	assertEqualBoard(t, flipped.Cells, []util.Cell{{X: 7, Y: 5}}, p)
+
+	// The world carries on from the edited cells, and 'b' undoes an edit like a step.
+	keyPresses <- 'b'
+	flipped, turn := awaitFlipped(t, events)
+	assert(t, turn == pausedAt, "Undoing an edit should not change the turn from %v, but it is %v", pausedAt, turn)
+	assertEqualBoard(t, flipped.Cells, []util.Cell{{X: 7, Y: 5}}, p)
+	keyPresses <- 'n'
+	_, turn = awaitFlipped(t, events)
+	assert(t, turn == pausedAt+1, "'n' should complete turn %v, not %v", pausedAt+1, turn)
+
+	keyPresses <- 'q'
+	for event := range events {
+		if e, ok := event.(gol.FinalTurnComplete); ok {
+			expected := []util.Cell{{X: 6, Y: 4}, {X: 6, Y: 5}, {X: 6, Y: 6}}
+			assertEqualBoard(t, e.Alive, expected, p)
+		}
+	}
+}
+
+func testEditDaemon(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "gol.sock")
+	listener, err := net.Listen("unix", socket)
+	util.Check(err)
+	empty := writePattern(t, "empty.cells", ".\n")
+	p := gol.Params{Turns: 100000000, Threads: 4, ImageWidth: 16, ImageHeight: 16, Input: empty, OutDir: t.TempDir()}
+	go gol.ServeDaemon(listener, p, nil)
+
+	events := make(chan gol.Event, 1000)
+	keyPresses := make(chan rune, 10)
+	edits := make(chan gol.CellEdit, 10)
+	_, err = gol.Attach(socket, events, keyPresses, edits)
+	util.Check(err)
+	keyPresses <- 'p'
+	awaitPaused(t, events)
+
+	edits <- gol.CellEdit{Cell: util.Cell{X: 3, Y: 4}, Alive: true}
+	flipped, _ := awaitFlipped(t, events)
+	assertEqualBoard(t, flipped.Cells, []util.Cell{{X: 3, Y: 4}}, p)
+
+	keyPresses <- 'k'
+	for event := range events {
+		if e, ok := event.(gol.FinalTurnComplete); ok {
+			assertEqualBoard(t, e.Alive, []util.Cell{{X: 3, Y: 4}}, p)
+		}
+	}
+}
+
+func keys(set map[util.Cell]bool) []util.Cell {
+	var cells []util.Cell
+	for cell := range set {
+		cells = append(cells, cell)
+	}
+	return cells
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+)
+
+// TestErrors tests that bad input files and failed writes are reported instead of panicking.
+func TestErrors(t *testing.T) {
+	notPgm := writePattern(t, "bad.pgm", "P6\n4 4\n255\n")
+	truncated := writePattern(t, "truncated.pgm", "P5\n4 4\n255\n\xff\xff\xff")
+	outside := writePattern(t, "glider.cells", ".O\n..O\nOOO\n")
+	outputFile := writePattern(t, "file", "not a directory")
+	badRule := writePattern(t, "bad.rle", "x = 3, y = 1, rule = B9/S23\n3o!\n")
+
+	tests := []struct {
+		name string
+		p    gol.Params
+	}{
+		{"missing", gol.Params{Turns: 1, Threads: 1, ImageWidth: 4, ImageHeight: 4, Input: "images/missing.pgm"}},
+		{"header", gol.Params{Turns: 1, Threads: 1, ImageWidth: 4, ImageHeight: 4, Input: notPgm}},
+		{"truncated", gol.Params{Turns: 1, Threads: 1, ImageWidth: 4, ImageHeight: 4, Input: truncated}},
+		{"size", gol.Params{Turns: 1, Threads: 1, ImageWidth: 16, ImageHeight: 16, Input: "images/64x64.pgm"}},
+		{"offset", gol.Params{Turns: 1, Threads: 1, ImageWidth: 4, ImageHeight: 4, Input: outside, OffsetX: 2}},
+		{"rule", gol.Params{Turns: 1, Threads: 1, ImageWidth: 16, ImageHeight: 16, Rule: "B9/S23"}},
+		{"pattern rule", gol.Params{Turns: 1, Threads: 1, ImageWidth: 16, ImageHeight: 16, Input: badRule}},
+		{"write", gol.Params{Turns: 1, Threads: 1, ImageWidth: 16, ImageHeight: 16, OutDir: outputFile}},
+	}
+	// Every goroutine of a run that failed should have finished, such as the io goroutine reading its input.
+	goroutines := runtime.NumGoroutine()
+	defer func() {
+		for i := 0; i < 100 && runtime.NumGoroutine() > goroutines; i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		leaked := runtime.NumGoroutine() - goroutines
+		assert(t, leaked <= 0, "%v goroutines are still running after the failed runs", leaked)
+	}()
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			events := make(chan gol.Event)
+			result := make(chan error, 1)
+			go func() {
+				result <- gol.RunE(test.p, events, nil)
+			}()
+
+			var reported error
+			quit := false
+			for event := range events {
+				switch e := event.(type) {
+				case gol.ErrorEvent:
+					reported = e.Err
+				case gol.StateChange:
+					quit = quit || e.NewState == gol.Quitting
+				}
+			}
+			err := <-result
+			assert(t, err != nil, "RunE should return an error")
+			assert(t, reported == err, "ErrorEvent should report %v, not %v", err, reported)
+			assert(t, quit, "A Quitting StateChange should be sent after the error")
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"sync"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+)
+
+// TestFaults tests that failed workers are recomputed, and that a worker that keeps failing stops the run cleanly.
+func TestFaults(t *testing.T) {
+	t.Run("recover", testFaultsRecover)
+	t.Run("abort", testFaultsAbort)
+}
+
+// runFaulty runs the Game of Life, returning the warnings and final world it reports and the error from RunE.
+func runFaulty(p gol.Params) ([]gol.WorkerWarning, gol.FinalTurnComplete, error) {
+	events := make(chan gol.Event)
+	result := make(chan error, 1)
+	go func() { result <- gol.RunE(p, events, nil) }()
+
+	var warnings []gol.WorkerWarning
+	var final gol.FinalTurnComplete
+	for event := range events {
+		switch e := event.(type) {
+		case gol.WorkerWarning:
+			warnings = append(warnings, e)
+		case gol.FinalTurnComplete:
+			final = e
+		}
+	}
+	return warnings, final, <-result
+}
+
+func testFaultsRecover(t *testing.T) {
+	var once sync.Once
+	p := gol.Params{Turns: 100, Threads: 4, ImageWidth: 64, ImageHeight: 64, OutDir: t.TempDir()}
+	p.WorkerFault = func(worker, turn int) {
+		if worker == 1 && turn == 10 {
+			once.Do(func() {
+				var strips []int
+				_ = strips[worker]
+			})
+		}
+	}
+
+	warnings, final, err := runFaulty(p)
+	assert(t, err == nil, "A worker that fails once should be recovered, not stop the run with %v", err)
+	assert(t, len(warnings) == 1, "Expected 1 WorkerWarning, got %v", len(warnings))
+	if len(warnings) == 1 {
+		w := warnings[0]
+		assert(t, w.Worker == 1 && w.StartY == 16 && w.EndY == 32 && w.CompletedTurns == 10,
+			"Expected worker 1 on rows 16-31 after turn 10, got %v", w)
+	}
+	assertEqualBoard(t, final.Alive, readAliveCells("check/images/64x64x100.pgm", 64, 64), p)
+}
+
+func testFaultsAbort(t *testing.T) {
+	p := gol.Params{Turns: 100, Threads: 4, ImageWidth: 64, ImageHeight: 64, OutDir: t.TempDir()}
+	p.WorkerFault = func(worker, turn int) {
+		if worker == 2 && turn == 5 {
+			panic("injected fault")
+		}
+	}
+
+	warnings, final, err := runFaulty(p)
+	assert(t, err != nil, "A worker that keeps failing should stop the run with an error")
+	assert(t, len(warnings) == 3, "Expected 3 WorkerWarnings, got %v", len(warnings))
+	assert(t, final.CompletedTurns == 5, "The run should stop after turn 5, not %v", final.CompletedTurns)
+
+	// The strips that did compute turn 6 are rolled back, so the final world is exactly turn 5.
+	p.Turns = 5
+	expected := referenceRun(readAliveCells("images/64x64.pgm", 64, 64), p)
+	assertEqualBoard(t, final.Alive, expected, p)
+}
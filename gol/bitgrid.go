@@ -0,0 +1,271 @@
+package gol
+
+import (
+	"math/bits"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// bitGrid is a world packed 64 cells to a word.
+// Cell (x, y) is bit x%64 of rows[y][x/64]; bits past the width in the last word of a row are always 0.
+// A worker's view of the world only allocates the rows it owns and its halo rows, and
+// takes the cells on the far side of its edges from columns when the topology needs them.
+type bitGrid struct {
+	width, height int
+	rows          [][]uint64
+	columns       *edgeColumns
+}
+
+// edgeColumns holds the leftmost and rightmost columns of the whole world.
+type edgeColumns struct {
+	left, right []bool
+}
+
+// wordsFor returns the number of words needed to hold a row of width cells.
+func wordsFor(width int) int {
+	return (width + 63) / 64
+}
+
+func newBitGrid(width, height int) *bitGrid {
+	rows := make([][]uint64, height)
+	for i := range rows {
+		rows[i] = make([]uint64, wordsFor(width))
+	}
+	return &bitGrid{width: width, height: height, rows: rows}
+}
+
+// clone returns a copy of a whole world.
+func (g *bitGrid) clone() *bitGrid {
+	clone := newBitGrid(g.width, g.height)
+	for y := range g.rows {
+		copy(clone.rows[y], g.rows[y])
+	}
+	return clone
+}
+
+func (g *bitGrid) get(x, y int) bool {
+	return g.rows[y][x/64]&(1<<uint(x%64)) != 0
+}
+
+func (g *bitGrid) set(x, y int, alive bool) {
+	if alive {
+		g.rows[y][x/64] |= 1 << uint(x%64)
+	} else {
+		g.rows[y][x/64] &^= 1 << uint(x%64)
+	}
+}
+
+// cell returns the state of a cell that may lie one cell outside the world, as seen through the topology.
+func (g *bitGrid) cell(topology Topology, x, y int) uint64 {
+	x, y, inside := topology.wrap(x, y, g.width, g.height)
+	switch {
+	case !inside:
+		return 0
+	case g.columns != nil && x == 0 && g.columns.left[y]:
+		return 1
+	case g.columns != nil && x != 0 && g.columns.right[y]:
+		return 1
+	case g.columns == nil && g.get(x, y):
+		return 1
+	}
+	return 0
+}
+
+func (g *bitGrid) aliveCells() []util.Cell {
+	var aliveCells []util.Cell
+	for y, row := range g.rows {
+		for k, word := range row {
+			for word != 0 {
+				aliveCells = append(aliveCells, util.Cell{X: 64*k + bits.TrailingZeros64(word), Y: y})
+				word &= word - 1
+			}
+		}
+	}
+	return aliveCells
+}
+
+func (g *bitGrid) aliveCount() int {
+	count := 0
+	for _, row := range g.rows {
+		for _, word := range row {
+			count += bits.OnesCount64(word)
+		}
+	}
+	return count
+}
+
+// flip toggles the given cells, applying the difference returned by diff.
+func (g *bitGrid) flip(cells []util.Cell) {
+	for _, cell := range cells {
+		g.rows[cell.Y][cell.X/64] ^= 1 << uint(cell.X%64)
+	}
+}
+
+// diff returns the cells that differ between g and previous.
+func (g *bitGrid) diff(previous *bitGrid) []util.Cell {
+	var flipped []util.Cell
+	for y := range g.rows {
+		for k := range g.rows[y] {
+			word := g.rows[y][k] ^ previous.rows[y][k]
+			for ; word != 0; word &= word - 1 {
+				flipped = append(flipped, util.Cell{X: 64*k + bits.TrailingZeros64(word), Y: y})
+			}
+		}
+	}
+	return flipped
+}
+
+// equal reports whether g and other hold the same cells.
+func (g *bitGrid) equal(other *bitGrid) bool {
+	for y := range g.rows {
+		for k, word := range g.rows[y] {
+			if word != other.rows[y][k] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// hash returns an FNV-1a hash of the cells, a word at a time.
+func (g *bitGrid) hash() uint64 {
+	h := uint64(14695981039346656037)
+	for _, row := range g.rows {
+		for _, word := range row {
+			h ^= word
+			h *= 1099511628211
+		}
+	}
+	return h
+}
+
+// reverseRow writes row mirrored left to right into dst.
+func reverseRow(dst, row []uint64, width int) {
+	for k := range dst {
+		dst[k] = 0
+	}
+	for x := 0; x < width; x++ {
+		if row[x/64]&(1<<uint(x%64)) != 0 {
+			mirrored := width - 1 - x
+			dst[mirrored/64] |= 1 << uint(mirrored%64)
+		}
+	}
+}
+
+// paddedRow fills buf, which must hold wordsFor(width+1) words, with row y of the world as seen through the topology.
+// The row may lie one row outside the world. Bit width of buf holds the cell beyond the right edge,
+// and the returned word holds the cell beyond the left edge in bit 0.
+func (g *bitGrid) paddedRow(topology Topology, y int, buf []uint64) uint64 {
+	words := wordsFor(g.width)
+	switch {
+	case y >= 0 && y < g.height:
+		copy(buf, g.rows[y])
+	case topology == Bounded || topology == Cylinder:
+		for k := 0; k < words; k++ {
+			buf[k] = 0
+		}
+	case topology == KleinBottle || topology == CrossSurface:
+		reverseRow(buf[:words], g.rows[(y+g.height)%g.height], g.width)
+	default:
+		copy(buf, g.rows[(y+g.height)%g.height])
+	}
+	if len(buf) > words {
+		buf[words] = 0
+	}
+	buf[g.width/64] |= g.cell(topology, g.width, y) << uint(g.width%64)
+	return g.cell(topology, -1, y)
+}
+
+// halfAdd and fullAdd add one bit from each of their arguments in all 64 lanes at once.
+func halfAdd(a, b uint64) (sum, carry uint64) {
+	return a ^ b, a & b
+}
+
+func fullAdd(a, b, c uint64) (sum, carry uint64) {
+	t := a ^ b
+	return t ^ c, a&b | t&c
+}
+
+// westOf and eastOf return word k of a padded row shifted so that each lane holds its west or east neighbour.
+func westOf(row []uint64, left uint64, k int) uint64 {
+	if k > 0 {
+		left = row[k-1] >> 63
+	}
+	return row[k]<<1 | left
+}
+
+func eastOf(row []uint64, k int) uint64 {
+	return row[k]>>1 | row[k+1]<<63
+}
+
+// apply returns the next state of 64 cells given their current state and
+// the four bits of their neighbour counts, using only bitwise operations.
+func (rule Rule) apply(self, n0, n1, n2, n3 uint64) uint64 {
+	var born, survive uint64
+	for n := uint(0); n <= 8; n++ {
+		if (rule.Birth|rule.Survival)&(1<<n) == 0 {
+			continue
+		}
+		count := ^uint64(0)
+		for i, bit := range [4]uint64{n0, n1, n2, n3} {
+			if n&(1<<uint(i)) != 0 {
+				count &= bit
+			} else {
+				count &^= bit
+			}
+		}
+		if rule.Birth&(1<<n) != 0 {
+			born |= count
+		}
+		if rule.Survival&(1<<n) != 0 {
+			survive |= count
+		}
+	}
+	return born&^self | survive&self
+}
+
+// nextRows writes rows [startY, endY) of the next generation into next and returns the cells that flipped.
+func (g *bitGrid) nextRows(next *bitGrid, rule Rule, topology Topology, startY, endY int) []util.Cell {
+	words := wordsFor(g.width)
+	var lastMask uint64 = ^uint64(0)
+	if g.width%64 != 0 {
+		lastMask = 1<<uint(g.width%64) - 1
+	}
+
+	above, middle, below := make([]uint64, words+1), make([]uint64, words+1), make([]uint64, words+1)
+	aboveLeft := g.paddedRow(topology, startY-1, above)
+	middleLeft := g.paddedRow(topology, startY, middle)
+
+	var flipped []util.Cell
+	for y := startY; y < endY; y++ {
+		belowLeft := g.paddedRow(topology, y+1, below)
+
+		for k := 0; k < words; k++ {
+			s0, c0 := fullAdd(westOf(above, aboveLeft, k), above[k], eastOf(above, k))
+			s1, c1 := fullAdd(westOf(below, belowLeft, k), below[k], eastOf(below, k))
+			s2, c2 := halfAdd(westOf(middle, middleLeft, k), eastOf(middle, k))
+			n0, c3 := fullAdd(s0, s1, s2)
+			t, d0 := fullAdd(c0, c1, c2)
+			n1, d1 := halfAdd(t, c3)
+			n2, n3 := halfAdd(d0, d1)
+
+			self := middle[k]
+			if k == words-1 {
+				self &= lastMask
+			}
+			word := rule.apply(self, n0, n1, n2, n3)
+			if k == words-1 {
+				word &= lastMask
+			}
+			next.rows[y][k] = word
+
+			for diff := word ^ self; diff != 0; diff &= diff - 1 {
+				flipped = append(flipped, util.Cell{X: 64*k + bits.TrailingZeros64(diff), Y: y})
+			}
+		}
+
+		above, middle, below = middle, below, above
+		aboveLeft, middleLeft = middleLeft, belowLeft
+	}
+	return flipped
+}
@@ -0,0 +1,190 @@
+package gol
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/rpc"
+	"sync"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/stubs"
+)
+
+// brokerStepBudget is how long the broker keeps turning before replying to a step that allows more than one turn.
+const brokerStepBudget = 20 * time.Millisecond
+
+// broker holds the world on behalf of a controller and farms every turn out to the worker servers,
+// each of which computes an equal strip of rows.
+type broker struct {
+	addresses []string
+	workers   []*rpc.Client
+
+	mu       sync.Mutex
+	world    *bitGrid
+	rule     Rule
+	topology Topology
+	alive    int
+
+	shutdown     chan struct{}
+	shutdownOnce sync.Once
+}
+
+// ServeBroker serves controllers on listener, using the worker servers at the given addresses,
+// until a controller shuts it down.
+func ServeBroker(listener net.Listener, servers []string) error {
+	b := &broker{addresses: servers, shutdown: make(chan struct{})}
+	for _, address := range servers {
+		client, err := rpc.Dial("tcp", address)
+		if err != nil {
+			b.closeWorkers()
+			listener.Close()
+			return fmt.Errorf("server %v: %v", address, err)
+		}
+		b.workers = append(b.workers, client)
+	}
+	if len(b.workers) == 0 {
+		listener.Close()
+		return errors.New("a broker needs at least one server")
+	}
+	return serve(listener, "Broker", b, b.shutdown)
+}
+
+// Start replaces the broker's world with the controller's initial world.
+func (b *broker) Start(req stubs.StartRequest, res *stubs.Empty) error {
+	rule, err := ParseRule(req.Rule)
+	if err != nil {
+		return err
+	}
+	topology, err := ParseTopology(req.Topology)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.world = &bitGrid{width: req.World.Width, height: req.World.Height, rows: req.World.Rows}
+	b.rule = rule
+	b.topology = topology
+	b.alive = b.world.aliveCount()
+	return nil
+}
+
+// Step runs turns until MaxTurns are complete or the step budget is spent, and reports the cells that flipped.
+func (b *broker) Step(req stubs.StepRequest, res *stubs.StepResponse) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.world == nil {
+		return errors.New("the broker has no world")
+	}
+
+	previous := b.world
+	deadline := time.Now().Add(brokerStepBudget)
+	for res.Turns == 0 || (res.Turns < req.MaxTurns && time.Now().Before(deadline)) {
+		if err := b.turn(); err != nil {
+			return err
+		}
+		res.Turns++
+	}
+	res.Flipped = b.world.diff(previous)
+	res.Alive = b.alive
+	return nil
+}
+
+// turn advances the world by one turn.
+func (b *broker) turn() error {
+	width, height := b.world.width, b.world.height
+	numWorkers := len(b.workers)
+	if numWorkers > height {
+		numWorkers = height
+	}
+
+	var left, right []bool
+	if b.topology == CrossSurface {
+		left, right = make([]bool, height), make([]bool, height)
+		for y := range left {
+			left[y] = b.world.get(0, y)
+			right[y] = b.world.get(width-1, y)
+		}
+	}
+
+	next := &bitGrid{width: width, height: height, rows: make([][]uint64, height)}
+	calls := make([]*rpc.Call, numWorkers)
+	sliceHeight := height / numWorkers
+	remainder := height % numWorkers
+	startY := 0
+	for i := range calls {
+		endY := startY + sliceHeight
+		if i < remainder {
+			endY++
+		}
+		rows := make([][]uint64, 0, endY-startY+2)
+		rows = append(rows, b.world.rows[(startY-1+height)%height])
+		rows = append(rows, b.world.rows[startY:endY]...)
+		rows = append(rows, b.world.rows[endY%height])
+
+		req := stubs.TurnRequest{
+			Width:    width,
+			Height:   height,
+			StartY:   startY,
+			EndY:     endY,
+			Rule:     b.rule.String(),
+			Topology: b.topology.String(),
+			Rows:     rows,
+			Left:     left,
+			Right:    right,
+		}
+		calls[i] = b.workers[i].Go(stubs.WorkerTurn, req, new(stubs.TurnResponse), nil)
+		startY = endY
+	}
+
+	alive := 0
+	var err error
+	startY = 0
+	for i, call := range calls {
+		<-call.Done
+		res := call.Reply.(*stubs.TurnResponse)
+		if call.Error != nil {
+			err = fmt.Errorf("server %v: %v", b.addresses[i], call.Error)
+			continue
+		}
+		copy(next.rows[startY:], res.Rows)
+		startY += len(res.Rows)
+		alive += res.Alive
+	}
+	if err != nil {
+		return err
+	}
+	b.world = next
+	b.alive = alive
+	return nil
+}
+
+// Stop discards the world once its controller has finished with it.
+func (b *broker) Stop(req stubs.Empty, res *stubs.Empty) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.world = nil
+	return nil
+}
+
+// Shutdown shuts down every worker server and then the broker itself.
+func (b *broker) Shutdown(req stubs.Empty, res *stubs.Empty) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var err error
+	for i, client := range b.workers {
+		if callErr := client.Call(stubs.WorkerShutdown, stubs.Empty{}, new(stubs.Empty)); callErr != nil && err == nil {
+			err = fmt.Errorf("server %v: %v", b.addresses[i], callErr)
+		}
+	}
+	b.closeWorkers()
+	b.shutdownOnce.Do(func() { close(b.shutdown) })
+	return err
+}
+
+func (b *broker) closeWorkers() {
+	for _, client := range b.workers {
+		client.Close()
+	}
+}
@@ -0,0 +1,58 @@
+package gol
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// parseCells reads a pattern in the plaintext format: '!' comment lines followed by
+// one line per row where '.' is a dead cell and 'O' an alive cell. Short rows are padded with dead cells.
+func parseCells(r io.Reader) (pattern, error) {
+	var p pattern
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if strings.HasPrefix(line, "!") {
+			continue
+		}
+		for x, c := range line {
+			switch c {
+			case 'O', '*':
+				p.cells = append(p.cells, util.Cell{X: x, Y: p.height})
+			case '.':
+			default:
+				return p, fmt.Errorf("plaintext pattern has unexpected %q on row %v", c, p.height)
+			}
+		}
+		if len(line) > p.width {
+			p.width = len(line)
+		}
+		p.height++
+	}
+	return p, scanner.Err()
+}
+
+// writeCells writes the world in the plaintext format, leaving out dead cells at the end of each row.
+func writeCells(w io.Writer, world [][]byte, _ string) error {
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "!Name: %vx%v\n", len(world[0]), len(world))
+	for _, row := range world {
+		end := len(row)
+		for end > 0 && row[end-1] == 0 {
+			end--
+		}
+		line := make([]byte, end)
+		for x := range line {
+			line[x] = '.'
+			if row[x] != 0 {
+				line[x] = 'O'
+			}
+		}
+		fmt.Fprintf(out, "%s\n", line)
+	}
+	return out.Flush()
+}
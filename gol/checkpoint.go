@@ -0,0 +1,66 @@
+package gol
+
+import (
+	"encoding/gob"
+	"fmt"
+	"os"
+)
+
+// checkpoint is everything needed to carry on a run from a completed turn.
+// It is written with encoding/gob, so its fields are exported.
+type checkpoint struct {
+	Turn   int
+	Rule   string // The canonical rule the world was evolving under, which may have come from the input.
+	Params Params
+	World  checkpointWorld
+}
+
+// checkpointWorld is a world packed 64 cells to a word, as in bitGrid.
+type checkpointWorld struct {
+	Width, Height int
+	Rows          [][]uint64
+}
+
+// writeCheckpoint writes a checkpoint to path. It is written alongside first and then moved into place,
+// so a run that is killed while checkpointing leaves the previous checkpoint intact.
+func writeCheckpoint(path string, cp checkpoint) error {
+	temporary := path + ".tmp"
+	file, err := os.Create(temporary)
+	if err != nil {
+		return err
+	}
+	if err = gob.NewEncoder(file).Encode(cp); err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(temporary)
+		return err
+	}
+	return os.Rename(temporary, path)
+}
+
+// readCheckpoint reads a checkpoint written by writeCheckpoint.
+func readCheckpoint(path string) (checkpoint, error) {
+	var cp checkpoint
+	file, err := os.Open(path)
+	if err != nil {
+		return cp, err
+	}
+	defer file.Close()
+	if err = gob.NewDecoder(file).Decode(&cp); err != nil {
+		return cp, fmt.Errorf("%v is not a checkpoint: %v", path, err)
+	}
+	w := cp.World
+	if w.Width <= 0 || w.Height <= 0 || len(w.Rows) != w.Height {
+		return cp, fmt.Errorf("%v has an invalid world", path)
+	}
+	for _, row := range w.Rows {
+		if len(row) != wordsFor(w.Width) {
+			return cp, fmt.Errorf("%v has an invalid world", path)
+		}
+	}
+	return cp, nil
+}
@@ -0,0 +1,145 @@
+package gol
+
+import (
+	"errors"
+	"fmt"
+	"net/rpc"
+
+	"uk.ac.bris.cs/gameoflife/stubs"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// clusterEngine runs each strip of the world on its own worker server. The workers are linked in a ring
+// and pass their boundary rows straight to each other, so only the flipped cells and alive counts
+// come back to the controller, which keeps its own copy of the world up to date from them.
+type clusterEngine struct {
+	addresses []string
+	workers   []*rpc.Client
+	rule      Rule
+	topology  Topology
+	world     *bitGrid
+	alive     int
+}
+
+func newClusterEngine(p Params, rule Rule, world *bitGrid) (*clusterEngine, error) {
+	if p.Topology == CrossSurface {
+		return nil, errors.New("a cluster cannot run a cross-surface, whose edges join strips that are not neighbours")
+	}
+	numWorkers := len(p.Workers)
+	if numWorkers > world.height {
+		numWorkers = world.height
+	}
+
+	c := &clusterEngine{addresses: p.Workers[:numWorkers], rule: rule, topology: p.Topology}
+	for _, address := range c.addresses {
+		client, err := rpc.Dial("tcp", address)
+		if err != nil {
+			c.stop()
+			return nil, fmt.Errorf("worker %v: %v", address, err)
+		}
+		c.workers = append(c.workers, client)
+	}
+	if err := c.load(world); err != nil {
+		c.stop()
+		return nil, err
+	}
+	return c, nil
+}
+
+// load gives every worker its strip of world, linked to the workers either side.
+func (c *clusterEngine) load(world *bitGrid) error {
+	height := world.height
+	numWorkers := len(c.workers)
+
+	// A worker has no neighbour across a bounded top or bottom edge, and is its own neighbour when it is alone.
+	wrapsVertically := c.topology != Bounded && c.topology != Cylinder
+	neighbour := func(i int) string {
+		if numWorkers == 1 || (!wrapsVertically && (i < 0 || i >= numWorkers)) {
+			return ""
+		}
+		return c.addresses[(i+numWorkers)%numWorkers]
+	}
+
+	sliceHeight := height / numWorkers
+	remainder := height % numWorkers
+	startY := 0
+	for i, client := range c.workers {
+		endY := startY + sliceHeight
+		if i < remainder {
+			endY++
+		}
+		req := stubs.InitRequest{
+			Width:    world.width,
+			Height:   height,
+			StartY:   startY,
+			EndY:     endY,
+			Rule:     c.rule.String(),
+			Topology: c.topology.String(),
+			Rows:     world.rows[startY:endY],
+			Above:    neighbour(i - 1),
+			Below:    neighbour(i + 1),
+		}
+		if err := client.Call(stubs.WorkerInit, req, new(stubs.Empty)); err != nil {
+			return fmt.Errorf("worker %v: %v", c.addresses[i], err)
+		}
+		startY = endY
+	}
+	c.world = world.clone()
+	c.alive = world.aliveCount()
+	return nil
+}
+
+// step has every worker compute one turn of its strip.
+func (c *clusterEngine) step(maxTurns int) (int, []util.Cell, error) {
+	calls := make([]*rpc.Call, len(c.workers))
+	for i, client := range c.workers {
+		calls[i] = client.Go(stubs.WorkerStep, stubs.Empty{}, new(stubs.StepResponse), nil)
+	}
+
+	var flipped []util.Cell
+	alive := 0
+	var err error
+	for i, call := range calls {
+		<-call.Done
+		if call.Error != nil {
+			err = fmt.Errorf("worker %v: %v", c.addresses[i], call.Error)
+			continue
+		}
+		res := call.Reply.(*stubs.StepResponse)
+		flipped = append(flipped, res.Flipped...)
+		alive += res.Alive
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	c.world.flip(flipped)
+	c.alive = alive
+	return 1, flipped, nil
+}
+
+func (c *clusterEngine) snapshot() *bitGrid {
+	return c.world.clone()
+}
+
+func (c *clusterEngine) aliveCount() int {
+	return c.alive
+}
+
+// shutdown shuts down every worker server.
+func (c *clusterEngine) shutdown() error {
+	var err error
+	for i, client := range c.workers {
+		if callErr := client.Call(stubs.WorkerShutdown, stubs.Empty{}, new(stubs.Empty)); callErr != nil && err == nil {
+			err = fmt.Errorf("worker %v: %v", c.addresses[i], callErr)
+		}
+	}
+	return err
+}
+
+// stop releases the workers' strips and hangs up. The workers may already have been shut down.
+func (c *clusterEngine) stop() {
+	for _, client := range c.workers {
+		_ = client.Call(stubs.WorkerStop, stubs.Empty{}, new(stubs.Empty))
+		client.Close()
+	}
+}
@@ -0,0 +1,56 @@
+package gol
+
+import "uk.ac.bris.cs/gameoflife/util"
+
+// maxCycleHistory is how many turns of hashes a cycleDetector remembers when looking back for the start of a cycle.
+const maxCycleHistory = 1 << 20
+
+// cycleDetector finds the first repeated world with Brent's algorithm, comparing worlds exactly.
+// The world it saves is moved up to the current turn every time the distance between them doubles,
+// so once it lies in the cycle the current world matches it after exactly one period.
+type cycleDetector struct {
+	world     *bitGrid // The current world, kept up to date from the flipped cells.
+	saved     *bitGrid
+	savedTurn int
+	power     int // How far the current world gets from the saved one before the saved one moves up.
+	hashes    []uint64
+	firstTurn int // The turn that hashes[0] was taken at.
+}
+
+func newCycleDetector(world *bitGrid, turn int) *cycleDetector {
+	return &cycleDetector{
+		world:     world.clone(),
+		saved:     world.clone(),
+		savedTurn: turn,
+		power:     1,
+		hashes:    []uint64{world.hash()},
+		firstTurn: turn,
+	}
+}
+
+// observe applies the cells that flipped to reach turn, which must follow the last turn observed.
+// Once the world repeats it returns the first turn of the cycle and its period.
+func (d *cycleDetector) observe(turn int, flipped []util.Cell) (start, period int, found bool) {
+	d.world.flip(flipped)
+	if len(d.hashes) == maxCycleHistory {
+		d.hashes = append(d.hashes[:0:0], d.hashes[maxCycleHistory/2:]...)
+		d.firstTurn += maxCycleHistory / 2
+	}
+	d.hashes = append(d.hashes, d.world.hash())
+
+	if d.world.equal(d.saved) {
+		period = turn - d.savedTurn
+		// The worlds before the saved one are only remembered by their hashes, so the start is found from those.
+		start = d.savedTurn
+		for start > d.firstTurn && d.hashes[start-1-d.firstTurn] == d.hashes[start-1+period-d.firstTurn] {
+			start--
+		}
+		return start, period, true
+	}
+	if turn-d.savedTurn == d.power {
+		d.saved = d.world.clone()
+		d.savedTurn = turn
+		d.power *= 2
+	}
+	return 0, 0, false
+}
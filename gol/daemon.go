@@ -0,0 +1,343 @@
+package gol
+
+import (
+	"encoding/gob"
+	"net"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+func init() {
+	for _, event := range []Event{
+		AliveCellsCount{}, ImageOutputComplete{}, StateChange{}, CellFlipped{}, CellsFlipped{},
+		TurnComplete{}, FinalTurnComplete{}, CheckpointComplete{}, ErrorEvent{}, WorkerWarning{},
+		CycleDetected{},
+	} {
+		gob.Register(event)
+	}
+	gob.Register(daemonError(""))
+}
+
+// daemonError carries the message of an event's error to a controller.
+type daemonError string
+
+func (err daemonError) Error() string {
+	return string(err)
+}
+
+// Events wait in a backlog of controllerBacklog to be written to a controller, so that a controller never holds
+// up the daemon. Once controllerBehind are waiting, the controller skips turns until it has caught up with half
+// of them, and is then sent the cells that changed meanwhile. A controller is detached when a write to it
+// blocks for controllerWriteTimeout, or if its backlog fills up anyway.
+const (
+	controllerBacklog      = 10000
+	controllerBehind       = controllerBacklog * 3 / 4
+	controllerWriteTimeout = 10 * time.Second
+)
+
+// controller is a connection from a process that is attached to a daemon.
+type controller struct {
+	conn    net.Conn
+	events  chan Event
+	written chan struct{} // Closed once the writer has stopped.
+	keys    chan rune
+	edits   chan CellEdit
+	gone    chan struct{}
+
+	world  *bitGrid // The world as the events queued so far leave it.
+	behind bool     // Set while turns are being skipped.
+}
+
+// control is a key press or a cell edit sent by a controller.
+type control struct {
+	Key  rune
+	Edit *CellEdit
+}
+
+// newController starts writing p and then the events sent to a controller on conn, and reading its controls.
+// The controller is first sent the cells alive in world and the turn and state.
+func newController(conn net.Conn, p Params, world *bitGrid, turn int, state State) *controller {
+	c := &controller{
+		conn:    conn,
+		events:  make(chan Event, controllerBacklog),
+		written: make(chan struct{}),
+		keys:    make(chan rune, 10),
+		edits:   make(chan CellEdit, 100),
+		gone:    make(chan struct{}),
+		world:   newBitGrid(world.width, world.height),
+	}
+	go c.write(p)
+	c.catchUp(world, turn)
+	c.send(StateChange{CompletedTurns: turn, NewState: state})
+	go func() {
+		defer close(c.keys)
+		decoder := gob.NewDecoder(conn)
+		for {
+			var msg control
+			if decoder.Decode(&msg) != nil {
+				return
+			}
+			if msg.Edit != nil {
+				select {
+				case c.edits <- *msg.Edit:
+				case <-c.gone:
+					return
+				}
+				continue
+			}
+			select {
+			case c.keys <- msg.Key:
+			case <-c.gone:
+				return
+			}
+		}
+	}()
+	return c
+}
+
+// write writes p and then the events queued for the controller until they run out or it is detached.
+// A failed write closes the connection, which ends the reader and so detaches the controller.
+func (c *controller) write(p Params) {
+	defer close(c.written)
+	defer c.conn.Close()
+	encoder := gob.NewEncoder(c.conn)
+	_ = c.conn.SetWriteDeadline(time.Now().Add(controllerWriteTimeout))
+	if encoder.Encode(p) != nil {
+		return
+	}
+	for {
+		select {
+		case event, ok := <-c.events:
+			if !ok {
+				return
+			}
+			_ = c.conn.SetWriteDeadline(time.Now().Add(controllerWriteTimeout))
+			if encoder.Encode(&event) != nil {
+				return
+			}
+		case <-c.gone:
+			return
+		}
+	}
+}
+
+// send queues an event to be written to the controller, returning false if it has fallen too far behind.
+func (c *controller) send(event Event) bool {
+	switch e := event.(type) {
+	case ErrorEvent:
+		e.Err = daemonError(e.Err.Error())
+		event = e
+	case WorkerWarning:
+		e.Err = daemonError(e.Err.Error())
+		event = e
+	}
+	select {
+	case c.events <- event:
+		return true
+	default:
+		return false
+	}
+}
+
+// forward queues an event of the simulation, after which the world is as given, to be written to the controller.
+// A controller that is skipping turns catches up before any other event, so it sees them with the world they go with.
+// It returns false if the controller has fallen too far behind.
+func (c *controller) forward(event Event, world *bitGrid) bool {
+	switch e := event.(type) {
+	case CellFlipped, CellsFlipped, TurnComplete:
+		if !c.behind && len(c.events) >= controllerBehind {
+			c.behind = true
+		}
+		if c.behind {
+			if e, ok := e.(TurnComplete); ok && len(c.events) <= controllerBehind/2 {
+				return c.catchUp(world, e.CompletedTurns)
+			}
+			return true
+		}
+		switch e := e.(type) {
+		case CellFlipped:
+			c.world.flip([]util.Cell{e.Cell})
+		case CellsFlipped:
+			c.world.flip(e.Cells)
+		}
+	default:
+		if c.behind && !c.catchUp(world, event.GetCompletedTurns()) {
+			return false
+		}
+	}
+	return c.send(event)
+}
+
+// catchUp sends the cells that differ between the controller's world and world, and completes the turn.
+func (c *controller) catchUp(world *bitGrid, turn int) bool {
+	if flipped := world.diff(c.world); len(flipped) > 0 {
+		if !c.send(CellsFlipped{CompletedTurns: turn, Cells: flipped}) {
+			return false
+		}
+	}
+	c.world = world.clone()
+	c.behind = false
+	return c.send(TurnComplete{CompletedTurns: turn})
+}
+
+// close detaches the controller, dropping any events not yet written to it.
+func (c *controller) close() {
+	close(c.gone)
+	c.conn.Close()
+}
+
+// finish detaches the controller once the events queued for it have been written.
+func (c *controller) finish() {
+	close(c.events)
+	<-c.written
+	close(c.gone)
+}
+
+// ServeDaemon runs the Game of Life until it finishes, while controllers attach to it one at a time on listener.
+// A controller receives the events of the simulation and forwards its key presses and cell edits, except that 'q' detaches it;
+// a controller that attaches mid-run is first sent the alive cells as a CellsFlipped event.
+// A controller that falls behind with the events skips turns, and one that stops reading is detached.
+// Local keyPresses are passed to the simulation as they are, so 'q' there stops it.
+func ServeDaemon(listener net.Listener, p Params, keyPresses <-chan rune) error {
+	defer listener.Close()
+	p, err := ResolveParams(p)
+	if err != nil {
+		return err
+	}
+
+	events := make(chan Event, 1000)
+	simulationKeys := make(chan rune, 10)
+	simulationEdits := make(chan CellEdit, 100)
+	result := make(chan error, 1)
+	go func() {
+		result <- RunEdits(p, events, simulationKeys, simulationEdits)
+	}()
+
+	attach := make(chan net.Conn)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			select {
+			case attach <- conn:
+			case <-done:
+				conn.Close()
+				return
+			}
+		}
+	}()
+
+	// The daemon follows the world through the events so that it can be replayed to a new controller.
+	world := newBitGrid(p.ImageWidth, p.ImageHeight)
+	turn := 0
+	state := Executing
+	var attached *controller
+	detach := func() {
+		if attached != nil {
+			attached.close()
+			attached = nil
+		}
+	}
+	defer detach()
+
+	for {
+		var controllerKeys <-chan rune
+		var controllerEdits <-chan CellEdit
+		if attached != nil {
+			controllerKeys = attached.keys
+			controllerEdits = attached.edits
+		}
+		select {
+		case event, ok := <-events:
+			if !ok {
+				if attached != nil {
+					attached.finish()
+					attached = nil
+				}
+				return <-result
+			}
+			switch e := event.(type) {
+			case CellFlipped:
+				world.set(e.Cell.X, e.Cell.Y, !world.get(e.Cell.X, e.Cell.Y))
+			case CellsFlipped:
+				world.flip(e.Cells)
+			case StateChange:
+				state = e.NewState
+			}
+			turn = event.GetCompletedTurns()
+			if attached != nil && !attached.forward(event, world) {
+				detach()
+			}
+
+		case conn := <-attach:
+			detach()
+			attached = newController(conn, p, world, turn, state)
+
+		case key, ok := <-controllerKeys:
+			if !ok || key == 'q' {
+				detach()
+			} else {
+				simulationKeys <- key
+			}
+
+		case edit := <-controllerEdits:
+			simulationEdits <- edit
+
+		case key := <-keyPresses:
+			simulationKeys <- key
+		}
+	}
+}
+
+// Attach connects a controller to the daemon listening on the unix socket at path and returns the Params it is running.
+// The daemon's events are sent on events, which is closed once the daemon detaches the controller,
+// and keyPresses and edits are forwarded to the daemon.
+func Attach(path string, events chan<- Event, keyPresses <-chan rune, edits <-chan CellEdit) (Params, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return Params{}, err
+	}
+	decoder := gob.NewDecoder(conn)
+	var p Params
+	if err = decoder.Decode(&p); err != nil {
+		conn.Close()
+		return Params{}, err
+	}
+
+	detached := make(chan struct{})
+	go func() {
+		defer close(events)
+		defer close(detached)
+		defer conn.Close()
+		for {
+			var event Event
+			if decoder.Decode(&event) != nil {
+				return
+			}
+			events <- event
+		}
+	}()
+	go func() {
+		encoder := gob.NewEncoder(conn)
+		for {
+			select {
+			case key := <-keyPresses:
+				if encoder.Encode(control{Key: key}) != nil {
+					return
+				}
+			case edit := <-edits:
+				if encoder.Encode(control{Edit: &edit}) != nil {
+					return
+				}
+			case <-detached:
+				return
+			}
+		}
+	}()
+	return p, nil
+}
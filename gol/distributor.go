@@ -5,121 +5,188 @@ import (
 	"strconv"
 	"sync"
 	"time"
+
 	"uk.ac.bris.cs/gameoflife/util"
 )
 
 type distributorChannels struct {
-	events     chan<- Event
-	ioCommand  chan<- ioCommand
-	ioIdle     <-chan bool
-	ioFilename chan<- string
-	ioOutput   chan<- uint8
-	ioInput    <-chan uint8
-	keyPresses <-chan rune
-}
-
-func calculateAliveCells(p Params, world [][]byte) []util.Cell {
-	var aliveCells []util.Cell
-	for y := 0; y < len(world); y++ {
-		for x := 0; x < len(world[0]); x++ {
-			if world[y][x] != 0 {
-				aliveCells = append(aliveCells, util.Cell{X: x, Y: y})
-			}
-		}
-	}
-	return aliveCells
+	events       chan<- Event
+	ioCommand    chan<- ioCommand
+	ioIdle       <-chan bool
+	ioFilename   chan<- string
+	ioOutput     chan<- uint8
+	ioInput      <-chan uint8
+	ioInfo       <-chan imageInfo
+	ioWritten    <-chan error
+	ioCheckpoint chan<- checkpoint
+	keyPresses   <-chan rune
+	edits        <-chan CellEdit
 }
 
-func calculateNextState(p Params, world [][]byte, startY, endY int) ([][]byte, []util.Cell) {
-	sliceHeight := endY - startY
-	width := len(world[0])
+func distributor(p Params, c distributorChannels) error {
+	turn := 0
+	paused := false
+	tps := p.TPS
+	quitting := false
+	killing := false
+	var runErr, stepErr error
+	var mu sync.Mutex
 
-	newSlice := make([][]byte, sliceHeight)
-	for i := range newSlice {
-		newSlice[i] = make([]byte, width)
+	defer close(c.ioCommand)
+	// fail stops the simulation before it has started executing.
+	fail := func(err error) error {
+		c.events <- ErrorEvent{CompletedTurns: turn, Err: err}
+		c.events <- StateChange{CompletedTurns: turn, NewState: Quitting}
+		close(c.events)
+		return err
 	}
 
-	height := len(world)
-	var localFlipped []util.Cell
-
-	for y := startY; y < endY; y++ {
-		for x := 0; x < width; x++ {
-			alive := 0
-
-			for dy := -1; dy <= 1; dy++ {
-				for dx := -1; dx <= 1; dx++ {
-					if dy == 0 && dx == 0 {
-						continue
-					}
-
-					nx, ny := (x+dx+width)%width, (y+dy+height)%height
-
-					if world[ny][nx] == 255 {
-						alive++
-					}
-				}
-			}
-
-			cellChanged := false
-			if world[y][x] == 255 {
-				if alive < 2 || alive > 3 {
-					newSlice[y-startY][x] = 0
-					cellChanged = true
-				} else {
-					newSlice[y-startY][x] = 255
-				}
-			} else {
-				if alive == 3 {
-					newSlice[y-startY][x] = 255
-					cellChanged = true
-				} else {
-					newSlice[y-startY][x] = 0
-				}
-			}
+	// The rule, which ResolveParams has checked, is parsed before the io goroutine starts reading the input.
+	rule, err := ParseRule(p.Rule)
+	if err != nil {
+		return fail(err)
+	}
 
-			if cellChanged {
-				localFlipped = append(localFlipped, util.Cell{X: x, Y: y})
-			}
+	if p.Resume != "" {
+		c.ioCommand <- ioResume
+	} else {
+		c.ioCommand <- ioInput
+		// Without -input, the world is read from the image of its size in the images directory.
+		filename := "images/" + strconv.Itoa(p.ImageWidth) + "x" + strconv.Itoa(p.ImageHeight) + ".pgm"
+		if p.Input != "" {
+			filename = p.Input
 		}
+		c.ioFilename <- filename
 	}
 
-	return newSlice, localFlipped
-}
-
-func worker(startY, endY int, p Params, world [][]byte) ([][]byte, []util.Cell) {
-	newWorldSlice, localFlipped := calculateNextState(p, world, startY, endY)
-	return newWorldSlice, localFlipped
-}
-
-func distributor(p Params, c distributorChannels) {
-	turn := 0
-	paused := false
-	quitting := false
-	var mu sync.Mutex
-
-	c.ioCommand <- ioInput
-	filename := strconv.Itoa(p.ImageWidth) + "x" + strconv.Itoa(p.ImageHeight)
-	c.ioFilename <- filename
-
-	World := make([][]byte, p.ImageHeight)
-	for i := range World {
-		World[i] = make([]byte, p.ImageWidth)
+	info := <-c.ioInfo
+	if info.err != nil {
+		return fail(info.err)
 	}
+	turn = info.turn
 
+	World := newBitGrid(p.ImageWidth, p.ImageHeight)
 	for y := 0; y < p.ImageHeight; y++ {
 		for x := 0; x < p.ImageWidth; x++ {
-			World[y][x] = <-c.ioInput
+			World.set(x, y, <-c.ioInput != 0)
 		}
 	}
 
-	initialAliveCells := calculateAliveCells(p, World)
+	initialAliveCells := World.aliveCells()
 	for _, cell := range initialAliveCells {
 		c.events <- CellFlipped{CompletedTurns: turn, Cell: cell}
 	}
 
+	// Warnings are raised while the turn loop holds mu.
+	warn := func(worker, startY, endY int, err error) {
+		c.events <- WorkerWarning{CompletedTurns: turn, Worker: worker, StartY: startY, EndY: endY, Err: err}
+	}
+	eng, err := newEngine(p, rule, World, warn)
+	if err != nil {
+		return fail(err)
+	}
+
 	stateChan := make(chan State, 1)
 	c.events <- StateChange{CompletedTurns: turn, NewState: Executing}
 
+	// Cycles are looked for a turn at a time, until one is found.
+	var cycles *cycleDetector
+	if p.DetectCycles || p.SkipCycles {
+		cycles = newCycleDetector(World, turn)
+	}
+	historyLength := p.History
+	if historyLength == 0 {
+		historyLength = defaultHistory
+	}
+	past := newHistory(p.ImageWidth, historyLength)
+
+	// advance has the engine run up to maxTurns turns and sends the cells that flipped. The caller holds mu.
+	advance := func(maxTurns int) error {
+		if cycles != nil {
+			maxTurns = 1
+		}
+		completed, flipped, err := eng.step(maxTurns)
+		if err != nil {
+			return err
+		}
+		if len(flipped) > 0 {
+			c.events <- CellsFlipped{CompletedTurns: turn + completed, Cells: flipped}
+		}
+		past.record(turn, flipped)
+		turn += completed
+		if cycles != nil {
+			if start, period, found := cycles.observe(turn, flipped); found {
+				cycles = nil
+				c.events <- CycleDetected{CompletedTurns: turn, Start: start, Period: period}
+				// The world comes back to where it is every period turns, so whole periods need not be run.
+				if p.SkipCycles {
+					turn = p.Turns - (p.Turns-turn)%period
+					past.clear()
+				}
+			}
+		}
+		return nil
+	}
+
+	// retreat undoes up to steps of the most recently remembered steps and sends the cells that flipped back.
+	// The caller holds mu.
+	retreat := func(steps int) error {
+		current := eng.snapshot()
+		world := current.clone()
+		from := turn
+		undone := 0
+		for ; undone < steps; undone++ {
+			start, flipped, ok := past.back()
+			if !ok {
+				break
+			}
+			world.flip(flipped)
+			from = start
+		}
+		if undone == 0 {
+			return nil
+		}
+		if err := eng.load(world); err != nil {
+			return err
+		}
+		turn = from
+		if cycles != nil {
+			cycles = newCycleDetector(world, turn)
+		}
+		if flipped := world.diff(current); len(flipped) > 0 {
+			c.events <- CellsFlipped{CompletedTurns: turn, Cells: flipped}
+		}
+		c.events <- TurnComplete{CompletedTurns: turn}
+		return nil
+	}
+
+	// edit applies cell edits and sends the cells that changed. An edit is remembered as a step that does not
+	// change the turn, so 'b' undoes it. The caller holds mu.
+	edit := func(changes []CellEdit) error {
+		world := eng.snapshot()
+		var flipped []util.Cell
+		for _, e := range changes {
+			x, y := e.Cell.X, e.Cell.Y
+			if x >= 0 && y >= 0 && x < world.width && y < world.height && world.get(x, y) != e.Alive {
+				world.set(x, y, e.Alive)
+				flipped = append(flipped, e.Cell)
+			}
+		}
+		if len(flipped) == 0 {
+			return nil
+		}
+		if err := eng.load(world); err != nil {
+			return err
+		}
+		past.record(turn, flipped)
+		if cycles != nil {
+			cycles = newCycleDetector(world, turn)
+		}
+		c.events <- CellsFlipped{CompletedTurns: turn, Cells: flipped}
+		c.events <- TurnComplete{CompletedTurns: turn}
+		return nil
+	}
+
 	done := make(chan struct{})
 	var wg sync.WaitGroup
 	wg.Add(1)
@@ -127,8 +194,42 @@ func distributor(p Params, c distributorChannels) {
 	ticker := time.NewTicker(2 * time.Second)
 	defer ticker.Stop()
 
+	var checkpointTicks <-chan time.Time
+	if p.Checkpoint != "" && p.CheckpointEvery > 0 {
+		checkpointTicker := time.NewTicker(p.CheckpointEvery)
+		defer checkpointTicker.Stop()
+		checkpointTicks = checkpointTicker.C
+	}
+
+	edits := c.edits
 	go func() {
 		defer wg.Done()
+		// wake tells a paused turn loop to check the state again. A wake-up that is already pending will do.
+		wake := func(state State) {
+			select {
+			case stateChan <- state:
+			default:
+			}
+		}
+		// quit stops the turn loop after its current turn.
+		quit := func() {
+			quitting = true
+			wake(Quitting)
+		}
+		// A failed write stops the run, as the next one would most likely fail too.
+		writeFailed := func(err error) {
+			if runErr != nil {
+				return
+			}
+			runErr = err
+			c.events <- ErrorEvent{CompletedTurns: turn, Err: err}
+			quit()
+		}
+		stepFailed := func(err error) {
+			stepErr = err
+			c.events <- ErrorEvent{CompletedTurns: turn, Err: err}
+			quit()
+		}
 		for {
 			select {
 			case key := <-c.keyPresses:
@@ -139,39 +240,88 @@ func distributor(p Params, c distributorChannels) {
 					if paused {
 						c.events <- StateChange{CompletedTurns: turn, NewState: Paused}
 					} else {
-						stateChan <- Executing
+						wake(Executing)
 						c.events <- StateChange{CompletedTurns: turn, NewState: Executing}
 					}
+				case 'n':
+					// Stepping only happens while paused, when the turn loop is waiting.
+					if paused && !quitting && turn < p.Turns {
+						if err := advance(1); err != nil {
+							stepFailed(err)
+						} else {
+							c.events <- TurnComplete{CompletedTurns: turn}
+						}
+					}
+				case 'b', 'r':
+					// 'b' steps back one step and 'r' rewinds as far as history goes, pausing the run to inspect.
+					if quitting {
+						break
+					}
+					if !paused {
+						paused = true
+						c.events <- StateChange{CompletedTurns: turn, NewState: Paused}
+					}
+					steps := 1
+					if key == 'r' {
+						steps = past.length()
+					}
+					if err := retreat(steps); err != nil {
+						stepFailed(err)
+					}
+				case '+', '-', '0':
+					tps = adjustTPS(tps, key)
+					wake(Executing)
 				case 's':
-
+					world := eng.snapshot()
+					err := saveWorld(p, world, turn, c)
+					if err == nil && p.Checkpoint != "" {
+						err = saveCheckpoint(p, rule, world, turn, c)
+					}
+					if err != nil {
+						writeFailed(err)
+					}
+				case 'q', 'k':
+					killing = key == 'k'
 					c.ioCommand <- ioCheckIdle
 					<-c.ioIdle
-
-					c.ioCommand <- ioOutput
-					outputFilename := fmt.Sprintf("%vx%vx%v", p.ImageWidth, p.ImageHeight, turn)
-					c.ioFilename <- outputFilename
-
-					for y := 0; y < len(World); y++ {
-						for x := 0; x < len(World[0]); x++ {
-							c.ioOutput <- World[y][x]
+					quit()
+				}
+				mu.Unlock()
+			case e, ok := <-edits:
+				if !ok {
+					edits = nil
+					break
+				}
+				// A drag sends a burst of edits, which are applied together.
+				burst := []CellEdit{e}
+				for pending := true; pending && edits != nil; {
+					select {
+					case e, ok := <-edits:
+						if !ok {
+							edits = nil
+						} else {
+							burst = append(burst, e)
 						}
+					default:
+						pending = false
+					}
+				}
+				mu.Lock()
+				if !quitting {
+					if err := edit(burst); err != nil {
+						stepFailed(err)
 					}
-
-					c.ioCommand <- ioCheckIdle
-					<-c.ioIdle
-
-					c.events <- ImageOutputComplete{CompletedTurns: turn, Filename: outputFilename}
-				case 'q':
-					quitting = true
-					c.ioCommand <- ioCheckIdle
-					<-c.ioIdle
-					stateChan <- Quitting
 				}
 				mu.Unlock()
 			case <-ticker.C:
 				mu.Lock()
-				aliveCells := calculateAliveCells(p, World)
-				c.events <- AliveCellsCount{CompletedTurns: turn, CellsCount: len(aliveCells)}
+				c.events <- AliveCellsCount{CompletedTurns: turn, CellsCount: eng.aliveCount()}
+				mu.Unlock()
+			case <-checkpointTicks:
+				mu.Lock()
+				if err := saveCheckpoint(p, rule, eng.snapshot(), turn, c); err != nil {
+					writeFailed(err)
+				}
 				mu.Unlock()
 			case <-done:
 				return
@@ -179,112 +329,158 @@ func distributor(p Params, c distributorChannels) {
 		}
 	}()
 
-	for turn < p.Turns {
+	var lastTurn time.Time
+	for {
 		mu.Lock()
-		World = parallel(p, World, turn, c)
-		pausedCopy := paused
-		quittingCopy := quitting
-		turn++
-		mu.Unlock()
-
-		c.events <- TurnComplete{CompletedTurns: turn}
-
-		if pausedCopy {
-			state := <-stateChan
-			if state == Quitting {
-				break
+		// A pause takes effect before the next turn, so that 'n' and 'b' start from the turn it reported.
+		for paused && !quitting {
+			mu.Unlock()
+			<-stateChan
+			mu.Lock()
+		}
+		// 'n' and 'b' change the turn, so it is only read with mu held.
+		if quitting || turn >= p.Turns {
+			mu.Unlock()
+			break
+		}
+		// Throttled turns are spaced out by waiting without mu. A change of rate or state cuts the wait short.
+		maxTurns := p.Turns - turn
+		if tps > 0 {
+			if wait := time.Until(lastTurn.Add(time.Second / time.Duration(tps))); wait > 0 {
+				mu.Unlock()
+				timer := time.NewTimer(wait)
+				select {
+				case <-timer.C:
+				case <-stateChan:
+				}
+				timer.Stop()
+				continue
 			}
+			maxTurns = 1
 		}
-
-		if quittingCopy {
+		lastTurn = time.Now()
+		if err := advance(maxTurns); err != nil {
+			stepErr = err
+			c.events <- ErrorEvent{CompletedTurns: turn, Err: err}
+			mu.Unlock()
 			break
 		}
+		// The turn is reported before 'b' or 'r' can take it back.
+		c.events <- TurnComplete{CompletedTurns: turn}
+		mu.Unlock()
 	}
 
-	mu.Lock()
-	alive := calculateAliveCells(p, World)
+	// Nothing else can touch the world or the io goroutine once the key press goroutine has returned.
+	close(done)
+	wg.Wait()
+
+	World = eng.snapshot()
+	alive := World.aliveCells()
 	c.events <- FinalTurnComplete{
 		CompletedTurns: turn,
 		Alive:          alive,
 	}
-	mu.Unlock()
 
-	c.ioCommand <- ioCheckIdle
-	<-c.ioIdle
-	c.ioCommand <- ioOutput
-	outputFilename := fmt.Sprintf("%vx%vx%v", p.ImageWidth, p.ImageHeight, turn)
-	c.ioFilename <- outputFilename
+	// The world is not saved again after a failed write.
+	err = runErr
+	if err == nil {
+		err = saveWorld(p, World, turn, c)
+		if err == nil && p.Checkpoint != "" {
+			err = saveCheckpoint(p, rule, World, turn, c)
+		}
+		if err != nil {
+			c.events <- ErrorEvent{CompletedTurns: turn, Err: err}
+		}
+	}
+	if err == nil {
+		err = stepErr
+	}
 
-	for y := 0; y < len(World); y++ {
-		for x := 0; x < len(World[0]); x++ {
-			c.ioOutput <- World[y][x]
+	// 'k' also shuts down the processes that an engine runs on, once the final world is saved.
+	if s, ok := eng.(shutdowner); ok && killing {
+		if shutdownErr := s.shutdown(); shutdownErr != nil {
+			c.events <- ErrorEvent{CompletedTurns: turn, Err: shutdownErr}
+			if err == nil {
+				err = shutdownErr
+			}
 		}
 	}
-	c.ioCommand <- ioCheckIdle
-	<-c.ioIdle
 
-	c.events <- ImageOutputComplete{CompletedTurns: turn, Filename: outputFilename}
+	// The engine is released before the events channel closes, so the run is over once it does.
+	eng.stop()
 
 	c.events <- StateChange{CompletedTurns: turn, NewState: Quitting}
 
-	close(done)
-
-	wg.Wait()
-
 	close(c.events)
+	return err
 }
 
-func parallel(p Params, world [][]byte, turn int, c distributorChannels) [][]byte {
-	height := len(world)
-	width := len(world[0])
-
-	newWorld := make([][]byte, height)
-	for i := range newWorld {
-		newWorld[i] = make([]byte, width)
-	}
-
-	numWorkers := p.Threads
-	if numWorkers > height {
-		numWorkers = height
+// defaultTPS is the rate that '-' first slows an unlimited run to, a turn for every frame of the SDL window.
+const defaultTPS = 60
+
+// adjustTPS returns the turns per second after '+' doubles the rate, '-' halves it or '0' lifts the limit.
+// A rate of 0 is unlimited.
+func adjustTPS(tps int, key rune) int {
+	switch {
+	case key == '0':
+		return 0
+	case key == '+' && tps > 0:
+		return tps * 2
+	case key == '-' && tps == 0:
+		return defaultTPS
+	case key == '-' && tps > 1:
+		return tps / 2
 	}
+	return tps
+}
 
-	var wg sync.WaitGroup
-	var mu sync.Mutex
-	var allFlippedCells []util.Cell
-
-	sliceHeight := height / numWorkers
-	remainder := height % numWorkers
+// saveWorld has the io goroutine write the world as <width>x<height>x<turn> and reports the result.
+func saveWorld(p Params, world *bitGrid, turn int, c distributorChannels) error {
+	c.ioCommand <- ioCheckIdle
+	<-c.ioIdle
 
-	startY := 0
+	c.ioCommand <- ioOutput
+	outputFilename := fmt.Sprintf("%vx%vx%v", p.ImageWidth, p.ImageHeight, turn)
+	c.ioFilename <- outputFilename
 
-	for i := 0; i < numWorkers; i++ {
-		endY := startY + sliceHeight
-		if i < remainder {
-			endY++
-		}
+	sendWorld(world, c)
+	if err := <-c.ioWritten; err != nil {
+		return err
+	}
 
-		wg.Add(1)
-		go func(startY, endY int) {
-			defer wg.Done()
-			newSlice, localFlipped := worker(startY, endY, p, world)
+	c.events <- ImageOutputComplete{CompletedTurns: turn, Filename: outputFilename}
+	return nil
+}
 
-			// Protect access to newWorld and allFlippedCells with a mutex
-			mu.Lock()
-			for i := 0; i < endY-startY; i++ {
-				newWorld[startY+i] = newSlice[i]
-			}
-			allFlippedCells = append(allFlippedCells, localFlipped...)
-			mu.Unlock()
-		}(startY, endY)
+// saveCheckpoint has the io goroutine write the world and the turn it was reached at to p.Checkpoint.
+func saveCheckpoint(p Params, rule Rule, world *bitGrid, turn int, c distributorChannels) error {
+	c.ioCommand <- ioCheckIdle
+	<-c.ioIdle
 
-		startY = endY
+	c.ioCommand <- ioCheckpoint
+	c.ioCheckpoint <- checkpoint{
+		Turn:   turn,
+		Rule:   rule.String(),
+		Params: p,
+		World:  checkpointWorld{Width: world.width, Height: world.height, Rows: world.rows},
+	}
+	if err := <-c.ioWritten; err != nil {
+		return err
 	}
 
-	wg.Wait()
+	c.events <- CheckpointComplete{CompletedTurns: turn, Filename: p.Checkpoint}
+	return nil
+}
 
-	if len(allFlippedCells) > 0 {
-		c.events <- CellsFlipped{CompletedTurns: turn + 1, Cells: allFlippedCells}
+// sendWorld streams the world to the io goroutine one byte per cell.
+func sendWorld(world *bitGrid, c distributorChannels) {
+	for y := 0; y < world.height; y++ {
+		for x := 0; x < world.width; x++ {
+			if world.get(x, y) {
+				c.ioOutput <- 255
+			} else {
+				c.ioOutput <- 0
+			}
+		}
 	}
-
-	return newWorld
 }
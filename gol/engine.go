@@ -0,0 +1,54 @@
+package gol
+
+import (
+	"fmt"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// engine is a backend that evolves the world on behalf of the distributor.
+// The distributor never calls an engine from more than one goroutine at a time.
+type engine interface {
+	// step advances the world by at least one and at most maxTurns turns.
+	// It returns the number of turns completed and the cells that flipped over those turns.
+	step(maxTurns int) (int, []util.Cell, error)
+	// snapshot returns a copy of the current world.
+	snapshot() *bitGrid
+	// load replaces the current world, which the engine does not keep hold of.
+	load(world *bitGrid) error
+	// aliveCount returns the number of alive cells in the current world.
+	aliveCount() int
+	// stop releases the engine's goroutines.
+	stop()
+}
+
+// newEngine starts the engine selected by p.Engine on the initial world.
+// With a broker or a cluster of workers the turns are run by other processes instead.
+// warn is called when a local worker fails and its strip is computed again.
+func newEngine(p Params, rule Rule, world *bitGrid, warn func(worker, startY, endY int, err error)) (engine, error) {
+	if p.Broker != "" || len(p.Workers) > 0 {
+		switch {
+		case p.Engine != "" && p.Engine != "parallel":
+			return nil, fmt.Errorf("the %v engine cannot run on other processes", p.Engine)
+		case p.Broker != "" && len(p.Workers) > 0:
+			return nil, fmt.Errorf("a broker and a cluster of workers cannot be used together")
+		case p.Broker != "":
+			return newRemoteEngine(p, rule, world)
+		default:
+			return newClusterEngine(p, rule, world)
+		}
+	}
+	switch p.Engine {
+	case "", "parallel":
+		return newWorkerPool(p, rule, world, warn), nil
+	case "hashlife":
+		return newHashLife(p, rule, world)
+	default:
+		return nil, fmt.Errorf("unknown engine %q: expected parallel or hashlife", p.Engine)
+	}
+}
+
+// shutdowner is implemented by engines that run on other processes, which 'k' shuts down along with the controller.
+type shutdowner interface {
+	shutdown() error
+}
@@ -78,6 +78,38 @@ type FinalTurnComplete struct {
 	Alive          []util.Cell
 }
 
+// `CheckpointComplete` is an Event notifying the user that a checkpoint has been written, from which
+// the run can be resumed at CompletedTurns.
+type CheckpointComplete struct {
+	CompletedTurns int
+	Filename       string
+}
+
+// `CycleDetected` is an Event notifying the user that the world at CompletedTurns has been seen before:
+// from turn Start on, the world repeats every Period turns.
+type CycleDetected struct {
+	CompletedTurns int
+	Start          int
+	Period         int
+}
+
+// `ErrorEvent` is an Event notifying the user that the simulation is shutting down because of an error,
+// such as a malformed input file or a failed write.
+// It is followed by the usual `StateChange` to `Quitting` once the world has been stopped.
+type ErrorEvent struct {
+	CompletedTurns int
+	Err            error
+}
+
+// `WorkerWarning` is an Event notifying the user that a parallel worker failed to compute its strip,
+// rows StartY to EndY-1, of the next turn. The strip is computed again from the previous generation.
+type WorkerWarning struct {
+	CompletedTurns int
+	Worker         int
+	StartY, EndY   int
+	Err            error
+}
+
 // String methods allow the different types of Events and States to be printed.
 
 func (state State) String() string {
@@ -149,6 +181,38 @@ func (event FinalTurnComplete) GetCompletedTurns() int {
 	return event.CompletedTurns
 }
 
+func (event CheckpointComplete) String() string {
+	return fmt.Sprintf("Checkpoint %v Done", event.Filename)
+}
+
+func (event CheckpointComplete) GetCompletedTurns() int {
+	return event.CompletedTurns
+}
+
+func (event CycleDetected) String() string {
+	return fmt.Sprintf("Cycle of period %v from turn %v", event.Period, event.Start)
+}
+
+func (event CycleDetected) GetCompletedTurns() int {
+	return event.CompletedTurns
+}
+
+func (event ErrorEvent) String() string {
+	return fmt.Sprintf("Error: %v", event.Err)
+}
+
+func (event ErrorEvent) GetCompletedTurns() int {
+	return event.CompletedTurns
+}
+
+func (event WorkerWarning) String() string {
+	return fmt.Sprintf("Warning: worker %v failed on rows %v-%v: %v", event.Worker, event.StartY, event.EndY-1, event.Err)
+}
+
+func (event WorkerWarning) GetCompletedTurns() int {
+	return event.CompletedTurns
+}
+
 // This might all seem like weird syntax to you...
 // You have however seen something similar to it before in first year.
 
@@ -1,15 +1,115 @@
 package gol
 
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
 // Params provides the details of how to run the Game of Life and which image to load.
 type Params struct {
 	Turns       int
 	Threads     int
 	ImageWidth  int
 	ImageHeight int
+	Rule        string   // Birth/survival rulestring such as "B36/S23"; empty means Conway's "B3/S23".
+	Topology    Topology // How the edges of the world are joined; the zero value is a torus.
+	Engine      string   // "parallel" (the default) or "hashlife".
+
+	Input        string // Image or .rle, .cells or .lif pattern to load instead of images/<ImageWidth>x<ImageHeight>.pgm.
+	OffsetX      int    // Column at which the origin of a pattern is placed.
+	OffsetY      int    // Row at which the origin of a pattern is placed.
+	OutputFormat string // "pgm" (the default), "pgm-plain", "pbm", "pbm-plain", "rle", "cells" or "lif".
+	OutDir       string // Directory that images are saved in; empty means "out".
+
+	Checkpoint      string        // File that checkpoints are written to on 's', on quitting and periodically; empty disables them.
+	CheckpointEvery time.Duration // How often to checkpoint; zero only checkpoints on 's' and on quitting.
+	Resume          string        // Checkpoint to carry on from; Turns still counts from the start of the original run.
+
+	DetectCycles bool // Look for the world repeating and send a CycleDetected event when it does.
+	SkipCycles   bool // Also skip whole periods of the cycle, straight to the last few turns before Turns.
+	History      int  // How many steps are remembered for 'b' and 'r' to go back over; 0 means 100 and -1 none.
+	TPS          int  // Most turns to run a second, which '+' and '-' double and halve; 0 is unlimited.
+
+	Broker  string   // Address of a broker to run the turns on, such as "127.0.0.1:8030"; empty runs them locally.
+	Workers []string // Addresses of worker servers to run the strips on as a cluster; empty runs them locally.
+
+	// WorkerFault is called by each parallel worker before it computes its strip of a turn, which is
+	// the number of turns the worker has completed. Tests make it panic to inject a failure.
+	WorkerFault func(worker, turn int)
+}
+
+// CellEdit asks for a cell to be made alive or dead, such as by clicking it in the SDL window.
+type CellEdit struct {
+	Cell  util.Cell
+	Alive bool
+}
+
+// ResolveParams fills in an ImageWidth or ImageHeight of 0 from the header of the input file, and an empty
+// Rule from the rule embedded in a pattern, checking that the rule is valid. When resuming, the size, rule and topology are all taken from the checkpoint.
+func ResolveParams(p Params) (Params, error) {
+	if p.Resume != "" {
+		cp, err := readCheckpoint(p.Resume)
+		if err != nil {
+			return p, err
+		}
+		if (p.ImageWidth != 0 && p.ImageWidth != cp.World.Width) || (p.ImageHeight != 0 && p.ImageHeight != cp.World.Height) {
+			return p, fmt.Errorf("%v is %vx%v, not %vx%v", p.Resume, cp.World.Width, cp.World.Height, p.ImageWidth, p.ImageHeight)
+		}
+		p.ImageWidth, p.ImageHeight = cp.World.Width, cp.World.Height
+		p.Rule = cp.Rule
+		p.Topology = cp.Params.Topology
+		return p, nil
+	}
+	if _, isPattern := patternFormatFor(p.Input); isPattern || p.ImageWidth <= 0 || p.ImageHeight <= 0 {
+		if p.Input == "" {
+			return p, errors.New("an input file is needed when the width or height is not given")
+		}
+		width, height, headerRule, err := readInputHeader(p)
+		if err != nil {
+			return p, err
+		}
+		if p.ImageWidth == 0 {
+			p.ImageWidth = width
+		}
+		if p.ImageHeight == 0 {
+			p.ImageHeight = height
+		}
+		if p.Rule == "" {
+			p.Rule = headerRule
+		}
+	}
+	// The rule is checked here, before the simulation starts reading the input.
+	if _, err := ParseRule(p.Rule); err != nil {
+		return p, err
+	}
+	return p, nil
 }
 
 // Run starts the processing of Game of Life. It should initialise channels and goroutines.
+// Errors are reported with an ErrorEvent; use RunE to receive them as well.
 func Run(p Params, events chan<- Event, keyPresses <-chan rune) {
+	_ = RunE(p, events, keyPresses)
+}
+
+// RunE is Run returning the error that stopped the Game of Life early, or nil if it ran to completion or was quit.
+// The error is also sent as an ErrorEvent before the events channel is closed.
+func RunE(p Params, events chan<- Event, keyPresses <-chan rune) error {
+	return RunEdits(p, events, keyPresses, nil)
+}
+
+// RunEdits is RunE that also applies the cell edits sent on edits between turns.
+// The cells that an edit changes are sent back as CellsFlipped, followed by a TurnComplete.
+func RunEdits(p Params, events chan<- Event, keyPresses <-chan rune, edits <-chan CellEdit) error {
+	p, err := ResolveParams(p)
+	if err != nil {
+		events <- ErrorEvent{Err: err}
+		events <- StateChange{NewState: Quitting}
+		close(events)
+		return err
+	}
 
 	//	TODO: Put the missing channels in here.
 
@@ -17,27 +117,37 @@ func Run(p Params, events chan<- Event, keyPresses <-chan rune) {
 	iofilename := make(chan string)
 	iooutput := make(chan uint8)
 	ioinput := make(chan uint8)
+	ioinfo := make(chan imageInfo)
+	iowritten := make(chan error)
+	iocheckpoint := make(chan checkpoint)
 
 	ioCommand := make(chan ioCommand)
 	ioIdle := make(chan bool)
 
 	ioChannels := ioChannels{
-		command:  ioCommand,
-		idle:     ioIdle,
-		filename: iofilename,
-		output:   iooutput,
-		input:    ioinput,
+		command:    ioCommand,
+		idle:       ioIdle,
+		filename:   iofilename,
+		output:     iooutput,
+		input:      ioinput,
+		info:       ioinfo,
+		written:    iowritten,
+		checkpoint: iocheckpoint,
 	}
 	go startIo(p, ioChannels)
 
 	distributorChannels := distributorChannels{
-		events:     events,
-		ioCommand:  ioCommand,
-		ioIdle:     ioIdle,
-		ioFilename: iofilename,
-		ioOutput:   iooutput,
-		ioInput:    ioinput,
-		keyPresses: keyPresses,
+		events:       events,
+		ioCommand:    ioCommand,
+		ioIdle:       ioIdle,
+		ioFilename:   iofilename,
+		ioOutput:     iooutput,
+		ioInput:      ioinput,
+		ioInfo:       ioinfo,
+		ioWritten:    iowritten,
+		ioCheckpoint: iocheckpoint,
+		keyPresses:   keyPresses,
+		edits:        edits,
 	}
-	distributor(p, distributorChannels)
+	return distributor(p, distributorChannels)
 }
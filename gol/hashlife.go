@@ -0,0 +1,282 @@
+package gol
+
+import (
+	"errors"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// node is a canonical quadtree node. A node at level k covers 2^k x 2^k cells,
+// and two nodes with the same contents are always the same pointer.
+type node struct {
+	nw, ne, sw, se *node
+	level          uint
+	empty          bool
+}
+
+type quad struct {
+	nw, ne, sw, se *node
+}
+
+type stepKey struct {
+	n *node
+	j uint
+}
+
+const (
+	// maxHashLifeNodes bounds the memo tables before they are thrown away and rebuilt from the current world.
+	maxHashLifeNodes = 1 << 20
+	// fastHashLifeStep and slowHashLifeStep bound how long one step should take before the jump size is grown or shrunk.
+	fastHashLifeStep = 50 * time.Millisecond
+	slowHashLifeStep = 500 * time.Millisecond
+)
+
+// hashLife is an engine that memoises the evolution of quadtree nodes so that
+// repetitive worlds can jump ahead by huge powers of two of turns at a time.
+// The torus is simulated as the infinite plane tiled with copies of the world,
+// so the world's width and height must be powers of two.
+type hashLife struct {
+	rule          Rule
+	width, height int
+	tileLevel     uint
+	tile          *node
+	world         *bitGrid
+	jump          uint
+
+	nodes   map[quad]*node
+	results map[stepKey]*node
+	leaves  [2]*node
+	empties []*node
+}
+
+func newHashLife(p Params, rule Rule, world *bitGrid) (*hashLife, error) {
+	if p.Topology != Torus {
+		return nil, errors.New("hashlife engine only supports the torus topology")
+	}
+	if !isPowerOfTwo(world.width) || !isPowerOfTwo(world.height) {
+		return nil, errors.New("hashlife engine needs a width and height that are powers of two")
+	}
+	if rule.Birth&1 != 0 {
+		return nil, errors.New("hashlife engine does not support B0 rules")
+	}
+
+	h := &hashLife{rule: rule, width: world.width, height: world.height}
+	size := world.width
+	if world.height > size {
+		size = world.height
+	}
+	for 1<<h.tileLevel < size {
+		h.tileLevel++
+	}
+	h.reset(world)
+	return h, nil
+}
+
+func isPowerOfTwo(n int) bool {
+	return n > 0 && n&(n-1) == 0
+}
+
+// reset discards the memo tables and rebuilds the tile from world.
+func (h *hashLife) reset(world *bitGrid) {
+	h.nodes = make(map[quad]*node)
+	h.results = make(map[stepKey]*node)
+	h.leaves = [2]*node{{empty: true}, {}}
+	h.empties = []*node{h.leaves[0]}
+	h.world = world
+	h.tile = h.build(0, 0, h.tileLevel)
+}
+
+// join returns the canonical node with the given children.
+func (h *hashLife) join(nw, ne, sw, se *node) *node {
+	key := quad{nw, ne, sw, se}
+	if n, ok := h.nodes[key]; ok {
+		return n
+	}
+	n := &node{
+		nw: nw, ne: ne, sw: sw, se: se,
+		level: nw.level + 1,
+		empty: nw.empty && ne.empty && sw.empty && se.empty,
+	}
+	h.nodes[key] = n
+	return n
+}
+
+func (h *hashLife) empty(level uint) *node {
+	for uint(len(h.empties)) <= level {
+		e := h.empties[len(h.empties)-1]
+		h.empties = append(h.empties, h.join(e, e, e, e))
+	}
+	return h.empties[level]
+}
+
+// build returns the node at the given level whose top left cell is (x, y) of the tiled plane.
+func (h *hashLife) build(x, y int, level uint) *node {
+	if level == 0 {
+		if h.world.get(x%h.width, y%h.height) {
+			return h.leaves[1]
+		}
+		return h.leaves[0]
+	}
+	half := 1 << (level - 1)
+	return h.join(
+		h.build(x, y, level-1), h.build(x+half, y, level-1),
+		h.build(x, y+half, level-1), h.build(x+half, y+half, level-1),
+	)
+}
+
+// render writes the alive cells of n, whose top left cell is (x, y), into world.
+func (h *hashLife) render(n *node, x, y int, world *bitGrid) {
+	if n.empty || x >= world.width || y >= world.height {
+		return
+	}
+	if n.level == 0 {
+		world.set(x, y, true)
+		return
+	}
+	half := 1 << (n.level - 1)
+	h.render(n.nw, x, y, world)
+	h.render(n.ne, x+half, y, world)
+	h.render(n.sw, x, y+half, world)
+	h.render(n.se, x+half, y+half, world)
+}
+
+// center returns the level k-1 node in the middle of a level k node.
+func (h *hashLife) center(n *node) *node {
+	return h.join(n.nw.se, n.ne.sw, n.sw.ne, n.se.nw)
+}
+
+// base evolves the middle 2x2 cells of a level 2 node by one turn.
+func (h *hashLife) base(n *node) *node {
+	var cells [4][4]bool
+	for i, child := range [4]*node{n.nw, n.ne, n.sw, n.se} {
+		for j, leaf := range [4]*node{child.nw, child.ne, child.sw, child.se} {
+			cells[i/2*2+j/2][i%2*2+j%2] = !leaf.empty
+		}
+	}
+	var next [4]*node
+	for i := range next {
+		y, x := 1+i/2, 1+i%2
+		neighbours := 0
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				if (dx != 0 || dy != 0) && cells[y+dy][x+dx] {
+					neighbours++
+				}
+			}
+		}
+		if h.rule.next(cells[y][x], neighbours) {
+			next[i] = h.leaves[1]
+		} else {
+			next[i] = h.leaves[0]
+		}
+	}
+	return h.join(next[0], next[1], next[2], next[3])
+}
+
+// advance returns the middle level k-1 node of a level k node after 2^j turns, where j <= k-2.
+func (h *hashLife) advance(n *node, j uint) *node {
+	if n.empty {
+		return h.empty(n.level - 1)
+	}
+	key := stepKey{n, j}
+	if result, ok := h.results[key]; ok {
+		return result
+	}
+
+	var result *node
+	if n.level == 2 {
+		result = h.base(n)
+	} else {
+		// The nine overlapping level k-1 nodes that tile the middle of n.
+		a, b, c, d := n.nw, n.ne, n.sw, n.se
+		nine := [9]*node{
+			a, h.join(a.ne, b.nw, a.se, b.sw), b,
+			h.join(a.sw, a.se, c.nw, c.ne), h.join(a.se, b.sw, c.ne, d.nw), h.join(b.sw, b.se, d.nw, d.ne),
+			c, h.join(c.ne, d.nw, c.se, d.sw), d,
+		}
+
+		// At full speed both halves of the jump advance; otherwise only the second half does.
+		var inner [9]*node
+		for i, m := range nine {
+			if j == n.level-2 {
+				inner[i] = h.advance(m, j-1)
+			} else {
+				inner[i] = h.center(m)
+			}
+		}
+		second := j
+		if j == n.level-2 {
+			second = j - 1
+		}
+		result = h.join(
+			h.advance(h.join(inner[0], inner[1], inner[3], inner[4]), second),
+			h.advance(h.join(inner[1], inner[2], inner[4], inner[5]), second),
+			h.advance(h.join(inner[3], inner[4], inner[6], inner[7]), second),
+			h.advance(h.join(inner[4], inner[5], inner[7], inner[8]), second),
+		)
+	}
+	h.results[key] = result
+	return result
+}
+
+// step advances the tile by the largest power of two turns that fits in maxTurns and the current jump size.
+// The jump size grows while steps are fast, so repetitive worlds soon advance astronomically far at a time.
+func (h *hashLife) step(maxTurns int) (int, []util.Cell, error) {
+	j := h.jump
+	for j > 0 && 1<<j > maxTurns {
+		j--
+	}
+
+	start := time.Now()
+	level := h.tileLevel
+	if j > level {
+		level = j
+	}
+	// Tiling the plane to level+2 keeps the middle of the result aligned with whole copies of the tile.
+	root := h.tile
+	for root.level < level+2 {
+		root = h.join(root, root, root, root)
+	}
+	tile := h.advance(root, j)
+	for tile.level > h.tileLevel {
+		tile = tile.nw
+	}
+	h.tile = tile
+
+	previous := h.world
+	h.world = newBitGrid(h.width, h.height)
+	h.render(h.tile, 0, 0, h.world)
+
+	flipped := h.world.diff(previous)
+
+	elapsed := time.Since(start)
+	if elapsed < fastHashLifeStep && h.jump < 62 && j == h.jump {
+		h.jump++
+	} else if elapsed > slowHashLifeStep && h.jump > 0 {
+		h.jump--
+	}
+	if len(h.nodes) > maxHashLifeNodes {
+		h.reset(h.world)
+	}
+	return 1 << j, flipped, nil
+}
+
+func (h *hashLife) snapshot() *bitGrid {
+	world := newBitGrid(h.width, h.height)
+	for y := range world.rows {
+		copy(world.rows[y], h.world.rows[y])
+	}
+	return world
+}
+
+func (h *hashLife) load(world *bitGrid) error {
+	h.reset(world.clone())
+	return nil
+}
+
+func (h *hashLife) aliveCount() int {
+	return h.world.aliveCount()
+}
+
+func (h *hashLife) stop() {}
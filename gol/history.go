@@ -0,0 +1,76 @@
+package gol
+
+import "uk.ac.bris.cs/gameoflife/util"
+
+// defaultHistory is how many steps are remembered when Params.History is 0.
+const defaultHistory = 100
+
+// history is a ring buffer of the most recent steps, each stored as the cells that flipped over it,
+// so the world can be stepped back or rewound as far as the oldest step remembered.
+type history struct {
+	width       int
+	steps       []historyStep
+	first, size int
+}
+
+// historyStep is one step of the engine from turn from. Its flipped cells are packed as y*width+x.
+type historyStep struct {
+	from    int
+	flipped []uint32
+}
+
+// newHistory remembers up to length steps of a world width cells wide.
+func newHistory(width, length int) *history {
+	if length < 0 {
+		length = 0
+	}
+	return &history{width: width, steps: make([]historyStep, length)}
+}
+
+// record remembers a step, forgetting the oldest one once the buffer is full.
+func (h *history) record(from int, flipped []util.Cell) {
+	if len(h.steps) == 0 {
+		return
+	}
+	packed := make([]uint32, len(flipped))
+	for i, cell := range flipped {
+		packed[i] = uint32(cell.Y*h.width + cell.X)
+	}
+	step := historyStep{from: from, flipped: packed}
+	if h.size == len(h.steps) {
+		h.steps[h.first] = step
+		h.first = (h.first + 1) % len(h.steps)
+		return
+	}
+	h.steps[(h.first+h.size)%len(h.steps)] = step
+	h.size++
+}
+
+// back forgets the most recent step and returns the turn it started from and the cells that flipped over it.
+func (h *history) back() (int, []util.Cell, bool) {
+	if h.size == 0 {
+		return 0, nil, false
+	}
+	h.size--
+	i := (h.first + h.size) % len(h.steps)
+	step := h.steps[i]
+	h.steps[i] = historyStep{}
+	flipped := make([]util.Cell, len(step.flipped))
+	for j, index := range step.flipped {
+		flipped[j] = util.Cell{X: int(index) % h.width, Y: int(index) / h.width}
+	}
+	return step.from, flipped, true
+}
+
+// length returns how many steps are remembered.
+func (h *history) length() int {
+	return h.size
+}
+
+// clear forgets every step.
+func (h *history) clear() {
+	for i := range h.steps {
+		h.steps[i] = historyStep{}
+	}
+	h.first, h.size = 0, 0
+}
@@ -1,11 +1,10 @@
 package gol
 
 import (
+	"bufio"
 	"fmt"
 	"os"
-	"strconv"
-	"strings"
-	"uk.ac.bris.cs/gameoflife/util"
+	"path/filepath"
 )
 
 type ioChannels struct {
@@ -15,12 +14,26 @@ type ioChannels struct {
 	filename <-chan string
 	output   <-chan uint8
 	input    chan<- uint8
+	info     chan<- imageInfo
+	written  chan<- error
+
+	checkpoint <-chan checkpoint
+}
+
+// imageInfo is sent to the distributor before the cells of an input image.
+type imageInfo struct {
+	// err is set if the image could not be read, in which case no cells follow.
+	err error
+	// turn is the number of turns already completed when resuming from a checkpoint.
+	turn int
 }
 
 // ioState is the internal ioState of the io goroutine.
 type ioState struct {
 	params   Params
 	channels ioChannels
+	// rule is the rulestring written into output images that can carry one.
+	rule string
 }
 
 // ioCommand allows requesting behaviour from the io (pgm) goroutine.
@@ -31,32 +44,81 @@ type ioCommand uint8
 //		ioOutput 	= 0
 //		ioInput 	= 1
 //		ioCheckIdle = 2
+//		ioCheckpoint = 3
+//		ioResume = 4
 const (
 	ioOutput ioCommand = iota
 	ioInput
 	ioCheckIdle
+	ioCheckpoint
+	ioResume
 )
 
-// writePgmImage receives an array of bytes and writes it to a pgm file.
-func (io *ioState) writePgmImage() {
-	_ = os.Mkdir("out", os.ModePerm)
-
+// writeImage receives an array of bytes and writes it in the requested output format.
+// The result of the write is reported on the written channel once the whole world has been received.
+func (io *ioState) writeImage() {
 	// Request a filename from the distributor.
 	filename := <-io.channels.filename
+	world := io.receiveWorld()
 
-	file, ioError := os.Create("out/" + filename + ".pgm")
-	util.Check(ioError)
-	defer file.Close()
+	var err error
+	if format, ok := patternFormats[io.params.OutputFormat]; ok {
+		err = io.writePatternImage(filename, world, format)
+	} else if format, ok := pnmFormats[io.params.OutputFormat]; ok {
+		err = io.writePnmImage(filename, world, format)
+	} else if io.params.OutputFormat == "" {
+		err = io.writePnmImage(filename, world, pnmFormats["pgm"])
+	} else {
+		err = fmt.Errorf("unknown output format %q", io.params.OutputFormat)
+	}
+	if err == nil {
+		fmt.Println("File", filename, "output done!")
+	}
+	io.channels.written <- err
+}
 
-	_, _ = file.WriteString("P5\n")
-	//_, _ = file.WriteString("# PGM file writer by pnmmodules (https://github.com/owainkenwayucl/pnmmodules).\n")
-	_, _ = file.WriteString(strconv.Itoa(io.params.ImageWidth))
-	_, _ = file.WriteString(" ")
-	_, _ = file.WriteString(strconv.Itoa(io.params.ImageHeight))
-	_, _ = file.WriteString("\n")
-	_, _ = file.WriteString(strconv.Itoa(255))
-	_, _ = file.WriteString("\n")
+// outputPath returns the path of an output file in the output directory, creating the directory if needed.
+func (io *ioState) outputPath(filename string) (string, error) {
+	outDir := io.params.OutDir
+	if outDir == "" {
+		outDir = "out"
+	}
+	if err := os.MkdirAll(outDir, os.ModePerm); err != nil {
+		return "", err
+	}
+	return filepath.Join(outDir, filename), nil
+}
+
+// readInputHeader reads the width and height of the image or pattern p.Input from its header, along with
+// the rulestring embedded in a pattern. The size of a pattern includes the offset it is placed at.
+func readInputHeader(p Params) (int, int, string, error) {
+	if format, ok := patternFormatFor(p.Input); ok {
+		file, err := os.Open(p.Input)
+		if err != nil {
+			return 0, 0, "", err
+		}
+		defer file.Close()
+		pattern, err := format.read(file)
+		if err != nil {
+			return 0, 0, "", err
+		}
+		return p.OffsetX + pattern.width, p.OffsetY + pattern.height, pattern.rule, nil
+	}
 
+	file, err := os.Open(p.Input)
+	if err != nil {
+		return 0, 0, "", err
+	}
+	defer file.Close()
+	header, err := readPnmHeader(bufio.NewReader(file))
+	if err != nil {
+		return 0, 0, "", fmt.Errorf("%v: %v", p.Input, err)
+	}
+	return header.width, header.height, "", nil
+}
+
+// receiveWorld receives the world from the distributor one byte per cell.
+func (io *ioState) receiveWorld() [][]byte {
 	world := make([][]byte, io.params.ImageHeight)
 	for i := range world {
 		world[i] = make([]byte, io.params.ImageWidth)
@@ -64,64 +126,158 @@ func (io *ioState) writePgmImage() {
 
 	for y := 0; y < io.params.ImageHeight; y++ {
 		for x := 0; x < io.params.ImageWidth; x++ {
-			val := <-io.channels.output
-			//if val != 0 {
-			//	fmt.Println(x, y)
-			//}
-			world[y][x] = val
+			world[y][x] = <-io.channels.output
 		}
 	}
+	return world
+}
 
-	for y := 0; y < io.params.ImageHeight; y++ {
-		for x := 0; x < io.params.ImageWidth; x++ {
-			_, ioError = file.Write([]byte{world[y][x]})
-			util.Check(ioError)
-		}
+// writePatternImage writes the world to a pattern file.
+func (io *ioState) writePatternImage(filename string, world [][]byte, format patternFormat) error {
+	path, err := io.outputPath(filename + format.extension)
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
 	}
+	defer file.Close()
 
-	ioError = file.Sync()
-	util.Check(ioError)
+	if err = format.write(file, world, io.rule); err != nil {
+		return err
+	}
+	return file.Sync()
+}
 
-	fmt.Println("File", filename, "output done!")
+// writePnmImage writes the world to a pbm or pgm file.
+func (io *ioState) writePnmImage(filename string, world [][]byte, format pnmFormat) error {
+	path, err := io.outputPath(filename + format.extension)
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	//_, _ = file.WriteString("# PGM file writer by pnmmodules (https://github.com/owainkenwayucl/pnmmodules).\n")
+	if err = writePnm(file, world, format); err != nil {
+		return err
+	}
+	return file.Sync()
 }
 
-// readPgmImage opens a pgm file and sends its data as an array of bytes.
-func (io *ioState) readPgmImage() {
+// readImage opens an image or pattern file and sends its data as an array of bytes.
+// Files ending in .rle, .cells, .lif or .life are read as patterns; anything else is read as a pbm or pgm image.
+// If the file cannot be read the error is sent in place of the image.
+func (io *ioState) readImage() {
 
 	// Request a filename from the distributor.
 	filename := <-io.channels.filename
 
-	data, ioError := os.ReadFile("images/" + filename + ".pgm")
-	util.Check(ioError)
+	var image []byte
+	var err error
+	if format, ok := patternFormatFor(filename); ok {
+		image, err = io.readPatternImage(filename, format)
+	} else {
+		image, err = io.readPnmImage(filename)
+	}
+	if err != nil {
+		io.channels.info <- imageInfo{err: err}
+		return
+	}
+	io.channels.info <- imageInfo{}
+
+	for _, b := range image {
+		io.channels.input <- b
+	}
+
+	fmt.Println("File", filename, "input done!")
+}
+
+// readPatternImage opens a pattern file and returns the pattern, placed at the requested offset, as an array of bytes.
+// The rule embedded in it has already been put in the Params by ResolveParams.
+func (io *ioState) readPatternImage(filename string, format patternFormat) ([]byte, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
 
-	fields := strings.Fields(string(data))
+	pattern, err := format.read(file)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", filename, err)
+	}
 
-	if fields[0] != "P5" {
-		panic("Not a pgm file")
+	image := make([]byte, io.params.ImageHeight*io.params.ImageWidth)
+	for _, cell := range pattern.cells {
+		x, y := io.params.OffsetX+cell.X, io.params.OffsetY+cell.Y
+		if x < 0 || y < 0 || x >= io.params.ImageWidth || y >= io.params.ImageHeight {
+			return nil, fmt.Errorf("pattern cell %v,%v at offset %v,%v is outside the %vx%v world",
+				cell.X, cell.Y, io.params.OffsetX, io.params.OffsetY, io.params.ImageWidth, io.params.ImageHeight)
+		}
+		image[y*io.params.ImageWidth+x] = 255
 	}
+	return image, nil
+}
 
-	width, _ := strconv.Atoi(fields[1])
-	if width != io.params.ImageWidth {
-		panic("Incorrect width")
+// readPnmImage opens a pbm or pgm file and returns its cells as an array of bytes.
+func (io *ioState) readPnmImage(filename string) ([]byte, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
 	}
+	defer file.Close()
 
-	height, _ := strconv.Atoi(fields[2])
-	if height != io.params.ImageHeight {
-		panic("Incorrect height")
+	header, cells, err := readPnm(file)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", filename, err)
+	}
+	if header.width != io.params.ImageWidth || header.height != io.params.ImageHeight {
+		return nil, fmt.Errorf("%v is %vx%v, expected %vx%v",
+			filename, header.width, header.height, io.params.ImageWidth, io.params.ImageHeight)
 	}
+	return cells, nil
+}
 
-	maxval, _ := strconv.Atoi(fields[3])
-	if maxval != 255 {
-		panic("Incorrect maxval/bit depth")
+// writeCheckpointFile receives a checkpoint and writes it to the checkpoint file.
+func (io *ioState) writeCheckpointFile() {
+	cp := <-io.channels.checkpoint
+	err := writeCheckpoint(io.params.Checkpoint, cp)
+	if err == nil {
+		fmt.Println("File", io.params.Checkpoint, "checkpoint done!")
 	}
+	io.channels.written <- err
+}
 
-	image := []byte(fields[4])
+// readCheckpointFile reads the checkpoint being resumed and sends its world like an image,
+// along with the turn it was taken at.
+func (io *ioState) readCheckpointFile() {
+	cp, err := readCheckpoint(io.params.Resume)
+	if err == nil && (cp.World.Width != io.params.ImageWidth || cp.World.Height != io.params.ImageHeight) {
+		err = fmt.Errorf("%v is %vx%v, expected %vx%v",
+			io.params.Resume, cp.World.Width, cp.World.Height, io.params.ImageWidth, io.params.ImageHeight)
+	}
+	if err != nil {
+		io.channels.info <- imageInfo{err: err}
+		return
+	}
+	io.channels.info <- imageInfo{turn: cp.Turn}
 
-	for _, b := range image {
-		io.channels.input <- b
+	world := &bitGrid{width: cp.World.Width, height: cp.World.Height, rows: cp.World.Rows}
+	for y := 0; y < world.height; y++ {
+		for x := 0; x < world.width; x++ {
+			if world.get(x, y) {
+				io.channels.input <- 255
+			} else {
+				io.channels.input <- 0
+			}
+		}
 	}
 
-	fmt.Println("File", filename, "input done!")
+	fmt.Println("File", io.params.Resume, "input done!")
 }
 
 // startIo should be the entrypoint of the io goroutine.
@@ -130,16 +286,23 @@ func startIo(p Params, c ioChannels) {
 		params:   p,
 		channels: c,
 	}
+	if rule, err := ParseRule(p.Rule); err == nil {
+		io.rule = rule.String()
+	}
 
 	for command := range io.channels.command {
 		// Block and wait for requests from the distributor
 		switch command {
 		case ioInput:
-			io.readPgmImage()
+			io.readImage()
 		case ioOutput:
-			io.writePgmImage()
+			io.writeImage()
 		case ioCheckIdle:
 			io.channels.idle <- true
+		case ioCheckpoint:
+			io.writeCheckpointFile()
+		case ioResume:
+			io.readCheckpointFile()
 		}
 	}
 }
@@ -0,0 +1,55 @@
+package gol
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// parseLife106 reads a pattern in the Life 1.06 format: a "#Life 1.06" header followed by
+// one "x y" line per alive cell. Coordinates may be negative, so such patterns need an offset to fit in the world.
+func parseLife106(r io.Reader) (pattern, error) {
+	var p pattern
+	scanner := bufio.NewScanner(r)
+	if !scanner.Scan() || strings.TrimSpace(scanner.Text()) != "#Life 1.06" {
+		if err := scanner.Err(); err != nil {
+			return p, err
+		}
+		return p, fmt.Errorf("life 1.06 pattern has no #Life 1.06 header")
+	}
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var cell util.Cell
+		if _, err := fmt.Sscanf(line, "%d %d", &cell.X, &cell.Y); err != nil {
+			return p, fmt.Errorf("life 1.06 pattern has an invalid cell %q", line)
+		}
+		p.cells = append(p.cells, cell)
+		if cell.X+1 > p.width {
+			p.width = cell.X + 1
+		}
+		if cell.Y+1 > p.height {
+			p.height = cell.Y + 1
+		}
+	}
+	return p, scanner.Err()
+}
+
+// writeLife106 writes the alive cells of the world in the Life 1.06 format.
+func writeLife106(w io.Writer, world [][]byte, _ string) error {
+	out := bufio.NewWriter(w)
+	fmt.Fprintln(out, "#Life 1.06")
+	for y, row := range world {
+		for x, b := range row {
+			if b != 0 {
+				fmt.Fprintf(out, "%v %v\n", x, y)
+			}
+		}
+	}
+	return out.Flush()
+}
@@ -0,0 +1,46 @@
+package gol
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// pattern is a set of alive cells read from a pattern file.
+// Cells are relative to the offset at which the pattern is placed in the world.
+type pattern struct {
+	width, height int
+	rule          string
+	cells         []util.Cell
+}
+
+// patternFormat reads and writes one of the text formats used to exchange Life patterns.
+type patternFormat struct {
+	extension string
+	read      func(r io.Reader) (pattern, error)
+	// write writes the whole world; formats that cannot carry a rule ignore it.
+	write func(w io.Writer, world [][]byte, rule string) error
+}
+
+// patternFormats are keyed by the names accepted in Params.OutputFormat.
+var patternFormats = map[string]patternFormat{
+	"rle":   {extension: ".rle", read: parseRle, write: writeRle},
+	"cells": {extension: ".cells", read: parseCells, write: writeCells},
+	"lif":   {extension: ".lif", read: parseLife106, write: writeLife106},
+}
+
+// patternFormatFor returns the pattern format of a file, chosen by its extension.
+func patternFormatFor(filename string) (patternFormat, bool) {
+	extension := strings.ToLower(filepath.Ext(filename))
+	if extension == ".life" {
+		extension = ".lif"
+	}
+	for _, format := range patternFormats {
+		if format.extension == extension {
+			return format, true
+		}
+	}
+	return patternFormat{}, false
+}
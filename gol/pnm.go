@@ -0,0 +1,255 @@
+package gol
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// pnmFormat is one of the netpbm image formats that the world can be saved in.
+type pnmFormat struct {
+	extension string
+	magic     string
+}
+
+// pnmFormats are keyed by the names accepted in Params.OutputFormat.
+var pnmFormats = map[string]pnmFormat{
+	"pgm":       {extension: ".pgm", magic: "P5"},
+	"pgm-plain": {extension: ".pgm", magic: "P2"},
+	"pbm":       {extension: ".pbm", magic: "P4"},
+	"pbm-plain": {extension: ".pbm", magic: "P1"},
+}
+
+// pnmHeader is the header of a netpbm image. The maxval of a bitmap is 1.
+type pnmHeader struct {
+	magic         string
+	width, height int
+	maxval        int
+}
+
+// readPnmHeader reads the header of a P1, P2, P4 or P5 image, leaving r at the first byte of the pixel data.
+func readPnmHeader(r *bufio.Reader) (pnmHeader, error) {
+	var header pnmHeader
+	magic, err := pnmToken(r)
+	if err != nil {
+		return header, err
+	}
+	header.magic = magic
+	switch magic {
+	case "P1", "P4":
+		header.maxval = 1
+	case "P2", "P5":
+	default:
+		return header, fmt.Errorf("not a pbm or pgm file")
+	}
+
+	fields := []*int{&header.width, &header.height}
+	if header.maxval == 0 {
+		fields = append(fields, &header.maxval)
+	}
+	for _, field := range fields {
+		token, err := pnmToken(r)
+		if err != nil {
+			return header, fmt.Errorf("truncated header")
+		}
+		if *field, err = strconv.Atoi(token); err != nil {
+			return header, fmt.Errorf("invalid header value %q", token)
+		}
+	}
+	if header.width <= 0 || header.height <= 0 {
+		return header, fmt.Errorf("invalid size %vx%v", header.width, header.height)
+	}
+	if header.maxval <= 0 || header.maxval > 65535 {
+		return header, fmt.Errorf("invalid maxval %v", header.maxval)
+	}
+	return header, nil
+}
+
+// pnmToken reads the next whitespace-separated token, skipping '#' comments.
+// The single whitespace character ending the token is consumed.
+func pnmToken(r *bufio.Reader) (string, error) {
+	var token []byte
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			if err == io.EOF && len(token) > 0 {
+				return string(token), nil
+			}
+			return "", err
+		}
+		switch {
+		case b == '#':
+			if len(token) > 0 {
+				return string(token), r.UnreadByte()
+			}
+			if _, err := r.ReadString('\n'); err != nil {
+				return "", err
+			}
+		case isPnmSpace(b):
+			if len(token) > 0 {
+				return string(token), nil
+			}
+		default:
+			token = append(token, b)
+		}
+	}
+}
+
+func isPnmSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\v' || b == '\f' || b == '\r'
+}
+
+// pnmCell thresholds a sample at half of maxval, returning 255 for an alive cell and 0 for a dead one.
+// In a bitmap, where 1 is black, the black pixels are the alive cells.
+func pnmCell(sample, maxval int) byte {
+	if sample*2 > maxval {
+		return 255
+	}
+	return 0
+}
+
+// readPnm reads a P1, P2, P4 or P5 image and returns its header and one byte per cell.
+func readPnm(r io.Reader) (pnmHeader, []byte, error) {
+	in := bufio.NewReader(r)
+	header, err := readPnmHeader(in)
+	if err != nil {
+		return header, nil, err
+	}
+	width, height, maxval := header.width, header.height, header.maxval
+	cells := make([]byte, width*height)
+
+	switch header.magic {
+	case "P5":
+		sampleBytes := 1
+		if maxval > 255 {
+			sampleBytes = 2
+		}
+		row := make([]byte, width*sampleBytes)
+		for y := 0; y < height; y++ {
+			if _, err := io.ReadFull(in, row); err != nil {
+				return header, nil, truncatedPnm(err, y*width, width*height)
+			}
+			for x := 0; x < width; x++ {
+				sample := int(row[x*sampleBytes])
+				if sampleBytes == 2 {
+					sample = sample<<8 | int(row[x*2+1])
+				}
+				if sample > maxval {
+					return header, nil, fmt.Errorf("sample %v is above maxval %v", sample, maxval)
+				}
+				cells[y*width+x] = pnmCell(sample, maxval)
+			}
+		}
+	case "P4":
+		row := make([]byte, (width+7)/8)
+		for y := 0; y < height; y++ {
+			if _, err := io.ReadFull(in, row); err != nil {
+				return header, nil, truncatedPnm(err, y*width, width*height)
+			}
+			for x := 0; x < width; x++ {
+				cells[y*width+x] = pnmCell(int(row[x/8]>>(7-x%8)&1), 1)
+			}
+		}
+	case "P2":
+		for i := range cells {
+			token, err := pnmToken(in)
+			if err != nil {
+				return header, nil, truncatedPnm(err, i, len(cells))
+			}
+			sample, err := strconv.Atoi(token)
+			if err != nil || sample < 0 || sample > maxval {
+				return header, nil, fmt.Errorf("invalid sample %q", token)
+			}
+			cells[i] = pnmCell(sample, maxval)
+		}
+	case "P1":
+		// The digits of a plain bitmap need not be separated by whitespace.
+		for i := 0; i < len(cells); {
+			b, err := in.ReadByte()
+			if err != nil {
+				return header, nil, truncatedPnm(err, i, len(cells))
+			}
+			switch {
+			case b == '0' || b == '1':
+				cells[i] = pnmCell(int(b-'0'), 1)
+				i++
+			case b == '#':
+				if _, err := in.ReadString('\n'); err != nil {
+					return header, nil, truncatedPnm(err, i, len(cells))
+				}
+			case !isPnmSpace(b):
+				return header, nil, fmt.Errorf("invalid bitmap digit %q", b)
+			}
+		}
+	}
+	return header, cells, nil
+}
+
+// truncatedPnm describes a read error that occurred after the given number of cells.
+func truncatedPnm(err error, read, total int) error {
+	if err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("truncated pixel data: %v of %v cells", read, total)
+	}
+	return err
+}
+
+// writePnm writes the world as an image in the given netpbm format.
+// Plain formats keep their lines within 70 characters.
+func writePnm(w io.Writer, world [][]byte, format pnmFormat) error {
+	height := len(world)
+	width := 0
+	if height > 0 {
+		width = len(world[0])
+	}
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "%v\n%v %v\n", format.magic, width, height)
+	if format.magic == "P2" || format.magic == "P5" {
+		fmt.Fprintf(out, "%v\n", 255)
+	}
+
+	column := 0
+	put := func(token string) {
+		if column > 0 && column+1+len(token) > 70 {
+			_ = out.WriteByte('\n')
+			column = 0
+		} else if column > 0 {
+			_ = out.WriteByte(' ')
+			column++
+		}
+		_, _ = out.WriteString(token)
+		column += len(token)
+	}
+
+	for _, row := range world {
+		switch format.magic {
+		case "P5":
+			_, _ = out.Write(row)
+		case "P4":
+			packed := make([]byte, (width+7)/8)
+			for x, cell := range row {
+				if cell != 0 {
+					packed[x/8] |= 0x80 >> (x % 8)
+				}
+			}
+			_, _ = out.Write(packed)
+		case "P2", "P1":
+			for _, cell := range row {
+				switch {
+				case format.magic == "P1" && cell != 0:
+					put("1")
+				case format.magic == "P1":
+					put("0")
+				case cell != 0:
+					put("255")
+				default:
+					put("0")
+				}
+			}
+			_ = out.WriteByte('\n')
+			column = 0
+		}
+	}
+	return out.Flush()
+}
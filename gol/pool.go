@@ -0,0 +1,305 @@
+package gol
+
+import (
+	"fmt"
+	"math/bits"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// strip is the horizontal band of rows [startY, endY) owned by one long-lived worker.
+// Its views of the world only allocate its own rows and the halo rows directly above and below them.
+type strip struct {
+	index        int
+	startY, endY int
+	rule         Rule
+	topology     Topology
+	current      *bitGrid
+	next         *bitGrid
+	turn         int
+	fault        func(worker, turn int)
+
+	// Halo rows are exchanged with the neighbouring workers each turn.
+	// A nil channel means there is no neighbour across a bounded edge.
+	fromAbove, fromBelow chan []uint64
+	toAbove, toBelow     chan<- []uint64
+}
+
+// stripResult is reported by a worker once it has computed a turn, or failed to.
+type stripResult struct {
+	index   int
+	flipped []util.Cell
+	alive   int
+	err     error
+}
+
+// stripCommand asks a worker to compute the next turn, or to compute the last one again after a failure.
+type stripCommand uint8
+
+const (
+	stripTurn stripCommand = iota
+	stripRetry
+)
+
+// maxStripAttempts is how many times a strip is computed in one turn before the pool gives up.
+const maxStripAttempts = 3
+
+// workerPool runs one worker per strip for the lifetime of the simulation.
+// The distributor only coordinates the turn barrier; between turns every worker is idle,
+// which is when the pool may read the strips directly.
+type workerPool struct {
+	width, height int
+	topology      Topology
+	strips        []*strip
+	commands      []chan stripCommand
+	results       chan stripResult
+	columns       *edgeColumns
+	alive         int
+	warn          func(worker, startY, endY int, err error)
+}
+
+// newWorkerPool starts the workers. warn is called whenever a worker fails and its strip is computed again.
+func newWorkerPool(p Params, rule Rule, world *bitGrid, warn func(worker, startY, endY int, err error)) *workerPool {
+	height := world.height
+	numWorkers := p.Threads
+	if numWorkers > height {
+		numWorkers = height
+	}
+	if numWorkers < 1 {
+		numWorkers = 1
+	}
+
+	pool := &workerPool{
+		width:    world.width,
+		height:   height,
+		topology: p.Topology,
+		strips:   make([]*strip, numWorkers),
+		commands: make([]chan stripCommand, numWorkers),
+		results:  make(chan stripResult, numWorkers),
+		alive:    world.aliveCount(),
+		warn:     warn,
+	}
+	if p.Topology == CrossSurface {
+		pool.columns = &edgeColumns{left: make([]bool, height), right: make([]bool, height)}
+	}
+
+	sliceHeight := height / numWorkers
+	remainder := height % numWorkers
+
+	startY := 0
+	for i := range pool.strips {
+		endY := startY + sliceHeight
+		if i < remainder {
+			endY++
+		}
+
+		s := &strip{
+			index:    i,
+			startY:   startY,
+			endY:     endY,
+			rule:     rule,
+			topology: p.Topology,
+			fault:    p.WorkerFault,
+			current:  &bitGrid{width: world.width, height: height, rows: make([][]uint64, height), columns: pool.columns},
+			next:     &bitGrid{width: world.width, height: height, rows: make([][]uint64, height)},
+		}
+		for y := startY; y < endY; y++ {
+			s.current.rows[y] = append([]uint64(nil), world.rows[y]...)
+			s.next.rows[y] = make([]uint64, wordsFor(world.width))
+		}
+		if numWorkers > 1 {
+			s.fromAbove = make(chan []uint64, 1)
+			s.fromBelow = make(chan []uint64, 1)
+			for _, y := range []int{(startY - 1 + height) % height, endY % height} {
+				s.current.rows[y] = make([]uint64, wordsFor(world.width))
+			}
+		}
+		pool.strips[i] = s
+		pool.commands[i] = make(chan stripCommand)
+
+		startY = endY
+	}
+
+	// Link each worker to its neighbours. Across a bounded top or bottom edge there is no neighbour.
+	wrapsVertically := p.Topology != Bounded && p.Topology != Cylinder
+	if numWorkers > 1 {
+		for i, s := range pool.strips {
+			if i > 0 || wrapsVertically {
+				s.toAbove = pool.strips[(i-1+numWorkers)%numWorkers].fromBelow
+			} else {
+				s.fromAbove = nil
+			}
+			if i < numWorkers-1 || wrapsVertically {
+				s.toBelow = pool.strips[(i+1)%numWorkers].fromAbove
+			} else {
+				s.fromBelow = nil
+			}
+		}
+	}
+
+	for i, s := range pool.strips {
+		go s.run(pool.commands[i], pool.results)
+	}
+	return pool
+}
+
+// run is the worker's loop. Each command computes one turn of the strip.
+// A retry computes the turn again from the same generation and halo rows.
+func (s *strip) run(commands <-chan stripCommand, results chan<- stripResult) {
+	for command := range commands {
+		if command == stripTurn {
+			s.exchangeHalos()
+		}
+		flipped, alive, err := s.compute()
+		if err == nil {
+			s.swap()
+			s.turn++
+		}
+		results <- stripResult{index: s.index, flipped: flipped, alive: alive, err: err}
+	}
+}
+
+// compute works out the next generation of the strip into next, recovering from a panic along the way.
+func (s *strip) compute() (flipped []util.Cell, alive int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	if s.fault != nil {
+		s.fault(s.index, s.turn)
+	}
+	flipped = s.current.nextRows(s.next, s.rule, s.topology, s.startY, s.endY)
+	for y := s.startY; y < s.endY; y++ {
+		for _, word := range s.next.rows[y] {
+			alive += bits.OnesCount64(word)
+		}
+	}
+	return flipped, alive, nil
+}
+
+// swap makes the computed generation current. Swapping again brings back the previous generation.
+func (s *strip) swap() {
+	for y := s.startY; y < s.endY; y++ {
+		s.current.rows[y], s.next.rows[y] = s.next.rows[y], s.current.rows[y]
+	}
+}
+
+// exchangeHalos sends the strip's top and bottom rows to its neighbours and receives their boundary rows in return.
+// A sent row is not written again until every worker has finished the turn, so only the receiver copies it.
+func (s *strip) exchangeHalos() {
+	height := s.current.height
+	if s.toAbove != nil {
+		s.toAbove <- s.current.rows[s.startY]
+	}
+	if s.toBelow != nil {
+		s.toBelow <- s.current.rows[s.endY-1]
+	}
+	if s.fromAbove != nil {
+		copy(s.current.rows[(s.startY-1+height)%height], <-s.fromAbove)
+	}
+	if s.fromBelow != nil {
+		copy(s.current.rows[s.endY%height], <-s.fromBelow)
+	}
+}
+
+// step advances every strip by one turn and returns the cells that flipped.
+// A strip whose worker fails is computed again; if it keeps failing, the turn is undone and an error returned.
+func (pool *workerPool) step(maxTurns int) (int, []util.Cell, error) {
+	if pool.columns != nil {
+		pool.gatherColumns()
+	}
+	for _, command := range pool.commands {
+		command <- stripTurn
+	}
+
+	results := make([]stripResult, len(pool.strips))
+	pending := len(pool.strips)
+	for attempt := 1; ; attempt++ {
+		var failed []int
+		for ; pending > 0; pending-- {
+			result := <-pool.results
+			results[result.index] = result
+			if result.err != nil {
+				failed = append(failed, result.index)
+			}
+		}
+		if len(failed) == 0 {
+			break
+		}
+
+		for _, i := range failed {
+			s := pool.strips[i]
+			if pool.warn != nil {
+				pool.warn(i, s.startY, s.endY, results[i].err)
+			}
+		}
+		if attempt == maxStripAttempts {
+			// Undo the strips that did finish so the world stays at one generation.
+			for i, s := range pool.strips {
+				if results[i].err == nil {
+					s.swap()
+					s.turn--
+				}
+			}
+			s := pool.strips[failed[0]]
+			return 0, nil, fmt.Errorf("worker %v failed %v times computing rows %v-%v: %v",
+				s.index, maxStripAttempts, s.startY, s.endY-1, results[s.index].err)
+		}
+		for _, i := range failed {
+			pool.commands[i] <- stripRetry
+		}
+		pending = len(failed)
+	}
+
+	var flipped []util.Cell
+	pool.alive = 0
+	for _, result := range results {
+		flipped = append(flipped, result.flipped...)
+		pool.alive += result.alive
+	}
+	return 1, flipped, nil
+}
+
+// gatherColumns copies the leftmost and rightmost columns out of the idle strips.
+func (pool *workerPool) gatherColumns() {
+	for _, s := range pool.strips {
+		for y := s.startY; y < s.endY; y++ {
+			pool.columns.left[y] = s.current.get(0, y)
+			pool.columns.right[y] = s.current.get(pool.width-1, y)
+		}
+	}
+}
+
+// snapshot assembles a copy of the whole world from the idle strips.
+func (pool *workerPool) snapshot() *bitGrid {
+	world := newBitGrid(pool.width, pool.height)
+	for _, s := range pool.strips {
+		for y := s.startY; y < s.endY; y++ {
+			copy(world.rows[y], s.current.rows[y])
+		}
+	}
+	return world
+}
+
+// load copies world into the idle strips.
+func (pool *workerPool) load(world *bitGrid) error {
+	for _, s := range pool.strips {
+		for y := s.startY; y < s.endY; y++ {
+			copy(s.current.rows[y], world.rows[y])
+		}
+	}
+	pool.alive = world.aliveCount()
+	return nil
+}
+
+func (pool *workerPool) aliveCount() int {
+	return pool.alive
+}
+
+// stop shuts down every worker.
+func (pool *workerPool) stop() {
+	for _, command := range pool.commands {
+		close(command)
+	}
+}
@@ -0,0 +1,75 @@
+package gol
+
+import (
+	"net/rpc"
+
+	"uk.ac.bris.cs/gameoflife/stubs"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// remoteEngine delegates turns to a broker. It keeps its own copy of the world up to date
+// from the cells that flip, so snapshots never need a round trip.
+type remoteEngine struct {
+	client   *rpc.Client
+	rule     Rule
+	topology Topology
+	world    *bitGrid
+	alive    int
+}
+
+func newRemoteEngine(p Params, rule Rule, world *bitGrid) (*remoteEngine, error) {
+	client, err := rpc.Dial("tcp", p.Broker)
+	if err != nil {
+		return nil, err
+	}
+	r := &remoteEngine{client: client, rule: rule, topology: p.Topology}
+	if err = r.load(world); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// load starts the broker again on world.
+func (r *remoteEngine) load(world *bitGrid) error {
+	req := stubs.StartRequest{
+		World:    stubs.World{Width: world.width, Height: world.height, Rows: world.rows},
+		Rule:     r.rule.String(),
+		Topology: r.topology.String(),
+	}
+	if err := r.client.Call(stubs.BrokerStart, req, new(stubs.Empty)); err != nil {
+		return err
+	}
+	r.world = world.clone()
+	r.alive = world.aliveCount()
+	return nil
+}
+
+func (r *remoteEngine) step(maxTurns int) (int, []util.Cell, error) {
+	var res stubs.StepResponse
+	if err := r.client.Call(stubs.BrokerStep, stubs.StepRequest{MaxTurns: maxTurns}, &res); err != nil {
+		return 0, nil, err
+	}
+	r.world.flip(res.Flipped)
+	r.alive = res.Alive
+	return res.Turns, res.Flipped, nil
+}
+
+func (r *remoteEngine) snapshot() *bitGrid {
+	return r.world.clone()
+}
+
+func (r *remoteEngine) aliveCount() int {
+	return r.alive
+}
+
+// shutdown shuts down the broker and its worker servers.
+func (r *remoteEngine) shutdown() error {
+	return r.client.Call(stubs.BrokerShutdown, stubs.Empty{}, new(stubs.Empty))
+}
+
+// stop releases the broker's world and hangs up. The broker may already have been shut down.
+func (r *remoteEngine) stop() {
+	_ = r.client.Call(stubs.BrokerStop, stubs.Empty{}, new(stubs.Empty))
+	r.client.Close()
+}
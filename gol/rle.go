@@ -0,0 +1,162 @@
+package gol
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// parseRle reads a pattern in the RLE format: '#' comment lines, an "x = m, y = n, rule = abc" header
+// and a body of runs where b is a dead cell, o an alive cell, $ the end of a row and ! the end of the pattern.
+func parseRle(r io.Reader) (pattern, error) {
+	var p pattern
+	scanner := bufio.NewScanner(r)
+	headerRead := false
+	x, y, count := 0, 0, 0
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "#r "):
+			p.rule = strings.TrimSpace(line[3:])
+			continue
+		case strings.HasPrefix(line, "#"):
+			continue
+		case !headerRead:
+			if err := p.parseHeader(line); err != nil {
+				return p, err
+			}
+			headerRead = true
+			continue
+		}
+
+		for _, r := range line {
+			switch {
+			case r >= '0' && r <= '9':
+				count = count*10 + int(r-'0')
+			case r == 'b' || r == 'o':
+				if count == 0 {
+					count = 1
+				}
+				if x+count > p.width || y >= p.height {
+					return p, fmt.Errorf("rle pattern is bigger than its header x = %v, y = %v", p.width, p.height)
+				}
+				if r == 'o' {
+					for i := 0; i < count; i++ {
+						p.cells = append(p.cells, util.Cell{X: x + i, Y: y})
+					}
+				}
+				x += count
+				count = 0
+			case r == '$':
+				if count == 0 {
+					count = 1
+				}
+				x, y = 0, y+count
+				count = 0
+			case r == '!':
+				return p, nil
+			case r == ' ' || r == '\t':
+			default:
+				return p, fmt.Errorf("rle pattern has unexpected %q", r)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return p, err
+	}
+	if !headerRead {
+		return p, fmt.Errorf("rle pattern has no x = .., y = .. header")
+	}
+	return p, fmt.Errorf("rle pattern has no terminating !")
+}
+
+// parseHeader reads an "x = m, y = n, rule = abc" header line.
+func (p *pattern) parseHeader(line string) error {
+	seenX, seenY := false, false
+	for _, field := range strings.Split(line, ",") {
+		keyValue := strings.SplitN(field, "=", 2)
+		if len(keyValue) != 2 {
+			return fmt.Errorf("rle header %q is not of the form x = m, y = n", line)
+		}
+		key, value := strings.TrimSpace(keyValue[0]), strings.TrimSpace(keyValue[1])
+		var err error
+		switch key {
+		case "x":
+			p.width, err = strconv.Atoi(value)
+			seenX = true
+		case "y":
+			p.height, err = strconv.Atoi(value)
+			seenY = true
+		case "rule":
+			// Golly appends the bounded grid to the rule after a colon, which is described by -topology instead.
+			p.rule = strings.SplitN(value, ":", 2)[0]
+		}
+		if err != nil || p.width < 0 || p.height < 0 {
+			return fmt.Errorf("rle header %q has an invalid %v", line, key)
+		}
+	}
+	if !seenX || !seenY {
+		return fmt.Errorf("rle header %q is not of the form x = m, y = n", line)
+	}
+	return nil
+}
+
+// writeRle writes the world in the RLE format, with body lines of at most 70 characters.
+func writeRle(w io.Writer, world [][]byte, rule string) error {
+	height := len(world)
+	width := 0
+	if height > 0 {
+		width = len(world[0])
+	}
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "x = %v, y = %v, rule = %v\n", width, height, rule)
+
+	line := ""
+	emit := func(count int, tag byte) {
+		run := string(tag)
+		if count > 1 {
+			run = strconv.Itoa(count) + run
+		}
+		if len(line)+len(run) > 70 {
+			fmt.Fprintln(out, line)
+			line = ""
+		}
+		line += run
+	}
+
+	// Dead cells at the end of a row and empty rows are folded into the following $.
+	pendingRows := 0
+	for y := 0; y < height; y++ {
+		x := 0
+		for x < width {
+			alive := world[y][x] != 0
+			run := 1
+			for x+run < width && (world[y][x+run] != 0) == alive {
+				run++
+			}
+			if alive || x+run < width {
+				if pendingRows > 0 {
+					emit(pendingRows, '$')
+					pendingRows = 0
+				}
+				if alive {
+					emit(run, 'o')
+				} else {
+					emit(run, 'b')
+				}
+			}
+			x += run
+		}
+		pendingRows++
+	}
+	emit(1, '!')
+	fmt.Fprintln(out, line)
+	return out.Flush()
+}
@@ -0,0 +1,110 @@
+package gol
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Rule is an outer-totalistic birth/survival rule.
+// Bit n of Birth is set if a dead cell with n alive neighbours becomes alive,
+// and bit n of Survival is set if an alive cell with n alive neighbours stays alive.
+type Rule struct {
+	Birth    uint16
+	Survival uint16
+}
+
+// Conway is the rule of Conway's Game of Life, B3/S23.
+var Conway = Rule{Birth: 1 << 3, Survival: 1<<2 | 1<<3}
+
+// ParseRule parses a rulestring in the "B36/S23" notation or the legacy "23/36" survival/birth notation.
+// An empty rulestring is Conway's Game of Life.
+func ParseRule(rulestring string) (Rule, error) {
+	s := strings.ToUpper(strings.TrimSpace(rulestring))
+	if s == "" {
+		return Conway, nil
+	}
+	if strings.ContainsAny(s, "BS") {
+		return parseBSRule(rulestring, s)
+	}
+	return parseLegacyRule(rulestring, s)
+}
+
+// parseBSRule parses the "B36/S23" notation. The slash is optional and the parts may come in either order.
+func parseBSRule(rulestring, s string) (Rule, error) {
+	var rule Rule
+	var current *uint16
+	seenB, seenS := false, false
+	for _, r := range s {
+		switch {
+		case r == 'B':
+			if seenB {
+				return Rule{}, fmt.Errorf("invalid rule %q: more than one birth part", rulestring)
+			}
+			seenB = true
+			current = &rule.Birth
+		case r == 'S':
+			if seenS {
+				return Rule{}, fmt.Errorf("invalid rule %q: more than one survival part", rulestring)
+			}
+			seenS = true
+			current = &rule.Survival
+		case r == '/':
+			current = nil
+		case r >= '0' && r <= '8':
+			if current == nil {
+				return Rule{}, fmt.Errorf("invalid rule %q: neighbour count outside a B or S part", rulestring)
+			}
+			*current |= 1 << uint(r-'0')
+		default:
+			return Rule{}, fmt.Errorf("invalid rule %q: unexpected %q", rulestring, r)
+		}
+	}
+	if !seenB {
+		return Rule{}, fmt.Errorf("invalid rule %q: missing birth part", rulestring)
+	}
+	return rule, nil
+}
+
+// parseLegacyRule parses the "23/3" notation, where the survival counts come before the birth counts.
+func parseLegacyRule(rulestring, s string) (Rule, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) != 2 {
+		return Rule{}, fmt.Errorf("invalid rule %q: expected B/S or S/B notation", rulestring)
+	}
+	var counts [2]uint16
+	for i, part := range parts {
+		for _, r := range part {
+			if r < '0' || r > '8' {
+				return Rule{}, fmt.Errorf("invalid rule %q: unexpected %q", rulestring, r)
+			}
+			counts[i] |= 1 << uint(r-'0')
+		}
+	}
+	return Rule{Birth: counts[1], Survival: counts[0]}, nil
+}
+
+// next returns whether a cell is alive in the next generation given its current state and number of alive neighbours.
+func (rule Rule) next(alive bool, neighbours int) bool {
+	if alive {
+		return rule.Survival&(1<<uint(neighbours)) != 0
+	}
+	return rule.Birth&(1<<uint(neighbours)) != 0
+}
+
+// String formats the rule in the "B36/S23" notation.
+func (rule Rule) String() string {
+	var sb strings.Builder
+	sb.WriteByte('B')
+	for n := 0; n <= 8; n++ {
+		if rule.Birth&(1<<uint(n)) != 0 {
+			sb.WriteByte(byte('0' + n))
+		}
+	}
+	sb.WriteString("/S")
+	for n := 0; n <= 8; n++ {
+		if rule.Survival&(1<<uint(n)) != 0 {
+			sb.WriteByte(byte('0' + n))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,57 @@
+package gol
+
+import (
+	"net"
+	"net/rpc"
+	"sync"
+	"time"
+)
+
+// shutdownGrace is how long a shutting down broker or worker waits for its clients to hang up.
+const shutdownGrace = time.Second
+
+// serve serves receiver's methods under name on listener until shutdown is closed.
+// The listener is then closed, and open connections are given a moment to receive their last replies.
+func serve(listener net.Listener, name string, receiver interface{}, shutdown <-chan struct{}) error {
+	server := rpc.NewServer()
+	if err := server.RegisterName(name, receiver); err != nil {
+		return err
+	}
+
+	var mu sync.Mutex
+	connections := map[net.Conn]bool{}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			connections[conn] = true
+			mu.Unlock()
+			go func() {
+				server.ServeConn(conn)
+				mu.Lock()
+				delete(connections, conn)
+				mu.Unlock()
+			}()
+		}
+	}()
+
+	<-shutdown
+	err := listener.Close()
+	for deadline := time.Now().Add(shutdownGrace); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		mu.Lock()
+		open := len(connections)
+		mu.Unlock()
+		if open == 0 {
+			break
+		}
+	}
+	mu.Lock()
+	for conn := range connections {
+		conn.Close()
+	}
+	mu.Unlock()
+	return err
+}
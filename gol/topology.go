@@ -0,0 +1,71 @@
+package gol
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Topology describes how the edges of the world are joined together.
+type Topology int
+
+const (
+	// Torus wraps the left edge onto the right edge and the top edge onto the bottom edge.
+	Torus Topology = iota
+	// Bounded treats every cell outside the world as permanently dead.
+	Bounded
+	// Cylinder wraps the left edge onto the right edge, with dead cells beyond the top and bottom edges.
+	Cylinder
+	// KleinBottle wraps like a torus, but the top and bottom edges are joined with a horizontal flip.
+	KleinBottle
+	// CrossSurface joins both pairs of edges with a flip, giving a real projective plane.
+	CrossSurface
+)
+
+var topologyNames = []string{
+	Torus:        "torus",
+	Bounded:      "bounded",
+	Cylinder:     "cylinder",
+	KleinBottle:  "klein-bottle",
+	CrossSurface: "cross-surface",
+}
+
+// ParseTopology parses one of "torus", "bounded", "cylinder", "klein-bottle" or "cross-surface".
+func ParseTopology(name string) (Topology, error) {
+	for topology, topologyName := range topologyNames {
+		if strings.EqualFold(strings.TrimSpace(name), topologyName) {
+			return Topology(topology), nil
+		}
+	}
+	return Torus, fmt.Errorf("invalid topology %q: expected one of %v", name, strings.Join(topologyNames, ", "))
+}
+
+func (topology Topology) String() string {
+	if topology < 0 || int(topology) >= len(topologyNames) {
+		return "Incorrect Topology"
+	}
+	return topologyNames[topology]
+}
+
+// wrap maps a coordinate that may lie up to one cell outside the world onto the cell it refers to.
+// It returns false if the coordinate lies beyond a bounded edge.
+func (topology Topology) wrap(x, y, width, height int) (int, int, bool) {
+	if y < 0 || y >= height {
+		switch topology {
+		case Bounded, Cylinder:
+			return 0, 0, false
+		case KleinBottle, CrossSurface:
+			x = width - 1 - x
+		}
+		y = (y + height) % height
+	}
+	if x < 0 || x >= width {
+		switch topology {
+		case Bounded:
+			return 0, 0, false
+		case CrossSurface:
+			y = height - 1 - y
+		}
+		x = (x + width) % width
+	}
+	return x, y, true
+}
@@ -0,0 +1,206 @@
+package gol
+
+import (
+	"errors"
+	"math/bits"
+	"net"
+	"net/rpc"
+	"sync"
+
+	"uk.ac.bris.cs/gameoflife/stubs"
+)
+
+// worker computes strips of turns, either for a broker, keeping no state between turns,
+// or as part of a cluster, holding its own strip and exchanging halo rows with its neighbours.
+type worker struct {
+	mu      sync.Mutex
+	cluster *clusterStrip
+
+	shutdown     chan struct{}
+	shutdownOnce sync.Once
+}
+
+// clusterStrip is the strip a worker holds in a cluster, with links to the workers holding the strips either side.
+// A nil link means there is no neighbour across that edge, or the worker is its own neighbour.
+type clusterStrip struct {
+	*strip
+	above, below *rpc.Client
+}
+
+// ServeWorker serves the turns of a broker or a cluster on listener until it is shut down.
+func ServeWorker(listener net.Listener) error {
+	w := &worker{shutdown: make(chan struct{})}
+	return serve(listener, "Worker", w, w.shutdown)
+}
+
+// Turn computes the next state of one strip of the world.
+func (w *worker) Turn(req stubs.TurnRequest, res *stubs.TurnResponse) error {
+	rule, err := ParseRule(req.Rule)
+	if err != nil {
+		return err
+	}
+	topology, err := ParseTopology(req.Topology)
+	if err != nil {
+		return err
+	}
+
+	// The strip's view of the world only holds its own rows and the rows either side of them.
+	current := &bitGrid{width: req.Width, height: req.Height, rows: make([][]uint64, req.Height)}
+	next := &bitGrid{width: req.Width, height: req.Height, rows: make([][]uint64, req.Height)}
+	current.rows[(req.StartY-1+req.Height)%req.Height] = req.Rows[0]
+	current.rows[req.EndY%req.Height] = req.Rows[len(req.Rows)-1]
+	for y := req.StartY; y < req.EndY; y++ {
+		current.rows[y] = req.Rows[1+y-req.StartY]
+		next.rows[y] = make([]uint64, wordsFor(req.Width))
+	}
+	if topology == CrossSurface {
+		current.columns = &edgeColumns{left: req.Left, right: req.Right}
+	}
+
+	current.nextRows(next, rule, topology, req.StartY, req.EndY)
+
+	res.Rows = next.rows[req.StartY:req.EndY]
+	for _, row := range res.Rows {
+		for _, word := range row {
+			res.Alive += bits.OnesCount64(word)
+		}
+	}
+	return nil
+}
+
+// Init replaces the worker's cluster strip and connects it to its neighbours.
+func (w *worker) Init(req stubs.InitRequest, res *stubs.Empty) error {
+	rule, err := ParseRule(req.Rule)
+	if err != nil {
+		return err
+	}
+	topology, err := ParseTopology(req.Topology)
+	if err != nil {
+		return err
+	}
+
+	s := &strip{
+		startY:   req.StartY,
+		endY:     req.EndY,
+		rule:     rule,
+		topology: topology,
+		current:  &bitGrid{width: req.Width, height: req.Height, rows: make([][]uint64, req.Height)},
+		next:     &bitGrid{width: req.Width, height: req.Height, rows: make([][]uint64, req.Height)},
+	}
+	for y := req.StartY; y < req.EndY; y++ {
+		s.current.rows[y] = req.Rows[y-req.StartY]
+		s.next.rows[y] = make([]uint64, wordsFor(req.Width))
+	}
+	c := &clusterStrip{strip: s}
+	if req.Above != "" {
+		if c.above, err = rpc.Dial("tcp", req.Above); err != nil {
+			return err
+		}
+		s.fromAbove = make(chan []uint64, 1)
+		s.current.rows[(req.StartY-1+req.Height)%req.Height] = make([]uint64, wordsFor(req.Width))
+	}
+	if req.Below != "" {
+		if c.below, err = rpc.Dial("tcp", req.Below); err != nil {
+			c.close()
+			return err
+		}
+		s.fromBelow = make(chan []uint64, 1)
+		s.current.rows[req.EndY%req.Height] = make([]uint64, wordsFor(req.Width))
+	}
+
+	w.mu.Lock()
+	previous := w.cluster
+	w.cluster = c
+	w.mu.Unlock()
+	if previous != nil {
+		previous.close()
+	}
+	return nil
+}
+
+// Step exchanges halo rows with the neighbouring workers and computes the next turn of the worker's cluster strip.
+func (w *worker) Step(req stubs.Empty, res *stubs.StepResponse) error {
+	w.mu.Lock()
+	c := w.cluster
+	w.mu.Unlock()
+	if c == nil {
+		return errors.New("the worker has no strip")
+	}
+
+	// A neighbour's row lands in a buffered channel, so sending never waits for the neighbour to step.
+	rows, height := c.current.rows, c.current.height
+	var calls []*rpc.Call
+	if c.above != nil {
+		calls = append(calls, c.above.Go(stubs.WorkerHalo, stubs.HaloRequest{Below: true, Row: rows[c.startY]}, new(stubs.Empty), nil))
+	}
+	if c.below != nil {
+		calls = append(calls, c.below.Go(stubs.WorkerHalo, stubs.HaloRequest{Row: rows[c.endY-1]}, new(stubs.Empty), nil))
+	}
+	for _, call := range calls {
+		if <-call.Done; call.Error != nil {
+			return call.Error
+		}
+	}
+	if c.fromAbove != nil {
+		copy(rows[(c.startY-1+height)%height], <-c.fromAbove)
+	}
+	if c.fromBelow != nil {
+		copy(rows[c.endY%height], <-c.fromBelow)
+	}
+
+	flipped, alive, err := c.compute()
+	if err != nil {
+		return err
+	}
+	c.swap()
+	res.Turns = 1
+	res.Flipped = flipped
+	res.Alive = alive
+	return nil
+}
+
+// Halo receives a boundary row from a neighbouring worker.
+func (w *worker) Halo(req stubs.HaloRequest, res *stubs.Empty) error {
+	w.mu.Lock()
+	c := w.cluster
+	w.mu.Unlock()
+	switch {
+	case c == nil:
+		return errors.New("the worker has no strip")
+	case req.Below && c.fromBelow != nil:
+		c.fromBelow <- req.Row
+	case !req.Below && c.fromAbove != nil:
+		c.fromAbove <- req.Row
+	default:
+		return errors.New("the worker has no neighbour on that side")
+	}
+	return nil
+}
+
+// Stop discards the worker's cluster strip.
+func (w *worker) Stop(req stubs.Empty, res *stubs.Empty) error {
+	w.mu.Lock()
+	c := w.cluster
+	w.cluster = nil
+	w.mu.Unlock()
+	if c != nil {
+		c.close()
+	}
+	return nil
+}
+
+// Shutdown stops the worker once it has replied.
+func (w *worker) Shutdown(req stubs.Empty, res *stubs.Empty) error {
+	w.Stop(req, res)
+	w.shutdownOnce.Do(func() { close(w.shutdown) })
+	return nil
+}
+
+func (c *clusterStrip) close() {
+	if c.above != nil {
+		c.above.Close()
+	}
+	if c.below != nil {
+		c.below.Close()
+	}
+}
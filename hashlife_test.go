@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// TestHashLife tests the hashlife engine against the check images and the alive counts of a very long run.
+func TestHashLife(t *testing.T) {
+	t.Run("images", testHashLifeImages)
+	t.Run("long", testHashLifeLong)
+}
+
+func testHashLifeImages(t *testing.T) {
+	tests := []gol.Params{
+		{ImageWidth: 16, ImageHeight: 16},
+		{ImageWidth: 64, ImageHeight: 64},
+		{ImageWidth: 512, ImageHeight: 512},
+	}
+	for _, p := range tests {
+		for _, turns := range []int{0, 1, 100} {
+			p.Turns = turns
+			p.Engine = "hashlife"
+			expectedAlive := readAliveCells(
+				"check/images/"+fmt.Sprintf("%vx%vx%v.pgm", p.ImageWidth, p.ImageHeight, turns),
+				p.ImageWidth,
+				p.ImageHeight,
+			)
+			testName := fmt.Sprintf("%dx%dx%d", p.ImageWidth, p.ImageHeight, p.Turns)
+			t.Run(testName, func(t *testing.T) {
+				events := make(chan gol.Event)
+				go gol.Run(p, events, nil)
+				var cells []util.Cell
+				for event := range events {
+					switch e := event.(type) {
+					case gol.FinalTurnComplete:
+						cells = e.Alive
+					}
+				}
+				assertEqualBoard(t, cells, expectedAlive, p)
+
+				cellsFromImage := readAliveCells(
+					"out/"+fmt.Sprintf("%vx%vx%v.pgm", p.ImageWidth, p.ImageHeight, turns),
+					p.ImageWidth,
+					p.ImageHeight,
+				)
+				assertEqualBoard(t, cellsFromImage, expectedAlive, p)
+			})
+		}
+	}
+}
+
+func testHashLifeLong(t *testing.T) {
+	p := gol.Params{
+		Turns:       10000000000,
+		ImageWidth:  512,
+		ImageHeight: 512,
+		Engine:      "hashlife",
+	}
+	events := make(chan gol.Event)
+	go gol.Run(p, events, nil)
+
+	timer := time.After(60 * time.Second)
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				t.Fatal("ERROR: Events closed before FinalTurnComplete")
+			}
+			switch e := event.(type) {
+			case gol.AliveCellsCount:
+				t.Log(e.CompletedTurns, e)
+			case gol.FinalTurnComplete:
+				assert(t, e.CompletedTurns == p.Turns, "FinalTurnComplete should be at turn %v, not %v", p.Turns, e.CompletedTurns)
+				assert(t, len(e.Alive) == 5565, "Expected 5565 alive cells after %v turns, got %v", p.Turns, len(e.Alive))
+				for range events {
+				}
+				return
+			}
+		case <-timer:
+			t.Fatalf("ERROR: %v turns did not complete in 60 seconds", p.Turns)
+		}
+	}
+}
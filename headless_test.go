@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/term"
+)
+
+// TestHeadless tests controlling a headless run with keys on stdin and with SIGUSR1 and SIGUSR2.
+func TestHeadless(t *testing.T) {
+	t.Run("stdin", testHeadlessStdin)
+	t.Run("signals", testHeadlessSignals)
+}
+
+// awaitSaved waits for the world to be saved and returns the name it was saved as.
+func awaitSaved(t *testing.T, events <-chan gol.Event) string {
+	for event := range events {
+		if e, ok := event.(gol.ImageOutputComplete); ok {
+			return e.Filename
+		}
+	}
+	t.Fatal("Events closed before the world was saved")
+	return ""
+}
+
+// awaitFinal waits for the final turn and the events to close, returning the turn.
+func awaitFinal(t *testing.T, events <-chan gol.Event) int {
+	final := -1
+	for event := range events {
+		if e, ok := event.(gol.FinalTurnComplete); ok {
+			final = e.CompletedTurns
+		}
+	}
+	assert(t, final >= 0, "The run should complete its final turn")
+	return final
+}
+
+func testHeadlessStdin(t *testing.T) {
+	p := gol.Params{Turns: 100000000, Threads: 4, ImageWidth: 64, ImageHeight: 64, OutDir: t.TempDir()}
+	keyPresses := make(chan rune, 10)
+	events := make(chan gol.Event, 1000)
+	in, stdin := io.Pipe()
+	restore := term.ForwardKeys(in, keyPresses)
+	defer restore()
+	go gol.Run(p, events, keyPresses)
+
+	write := func(keys string) {
+		if _, err := io.WriteString(stdin, keys); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("p")
+	pausedAt := awaitPaused(t, events)
+	write("s")
+	saved := awaitSaved(t, events)
+	expected := fmt.Sprintf("64x64x%v", pausedAt)
+	assert(t, saved == expected, "'s' on stdin should save the paused world as %v, not %v", expected, saved)
+	write("q")
+	final := awaitFinal(t, events)
+	assert(t, final == pausedAt, "'q' on stdin should quit at the paused turn %v, not %v", pausedAt, final)
+}
+
+func testHeadlessSignals(t *testing.T) {
+	p := gol.Params{Turns: 100000000, Threads: 4, ImageWidth: 64, ImageHeight: 64, OutDir: t.TempDir()}
+	keyPresses := make(chan rune, 10)
+	events := make(chan gol.Event, 1000)
+	sigusr(keyPresses)
+	go gol.Run(p, events, keyPresses)
+
+	signal := func(sig syscall.Signal) {
+		if err := syscall.Kill(os.Getpid(), sig); err != nil {
+			t.Fatal(err)
+		}
+	}
+	signal(syscall.SIGUSR2)
+	pausedAt := awaitPaused(t, events)
+	signal(syscall.SIGUSR1)
+	saved := awaitSaved(t, events)
+	expected := fmt.Sprintf("64x64x%v", pausedAt)
+	assert(t, saved == expected, "SIGUSR1 should save the paused world as %v, not %v", expected, saved)
+	keyPresses <- 'q'
+	awaitFinal(t, events)
+}
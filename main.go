@@ -3,13 +3,17 @@ package main
 import (
 	"flag"
 	"fmt"
+	"net"
 	"runtime"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"uk.ac.bris.cs/gameoflife/gol"
 	"uk.ac.bris.cs/gameoflife/sdl"
+	"uk.ac.bris.cs/gameoflife/term"
 )
 
 // main is the function called when starting Game of Life with 'go run .'
@@ -26,14 +30,14 @@ func main() {
 	flag.IntVar(
 		&params.ImageWidth,
 		"w",
-		512,
-		"Specify the width of the image. Defaults to 512.")
+		0,
+		"Specify the width of the image. Defaults to the width in the input header, or 512.")
 
 	flag.IntVar(
 		&params.ImageHeight,
 		"h",
-		512,
-		"Specify the height of the image. Defaults to 512.")
+		0,
+		"Specify the height of the image. Defaults to the height in the input header, or 512.")
 
 	flag.IntVar(
 		&params.Turns,
@@ -41,34 +45,274 @@ func main() {
 		10000000000,
 		"Specify the number of turns to process. Defaults to 10000000000.")
 
+	flag.StringVar(
+		&params.Rule,
+		"rule",
+		"",
+		"Specify the birth/survival rule, e.g. B36/S23 or 23/36. Defaults to the rule of an .rle input, or B3/S23.")
+
+	topology := flag.String(
+		"topology",
+		"torus",
+		"Specify the edge topology: torus, bounded, cylinder, klein-bottle or cross-surface. Defaults to torus.")
+
+	flag.StringVar(
+		&params.Engine,
+		"engine",
+		"parallel",
+		"Specify the simulation engine: parallel or hashlife. Defaults to parallel.")
+
+	flag.StringVar(
+		&params.Input,
+		"input",
+		"",
+		"Specify a .pgm or .pbm image or .rle, .cells or .lif pattern to load. Defaults to images/<w>x<h>.pgm.")
+
+	flag.StringVar(
+		&params.OutDir,
+		"outdir",
+		"out",
+		"Specify the directory to save images in. Defaults to out.")
+
+	offset := flag.String(
+		"offset",
+		"0,0",
+		"Specify the x,y position of the origin of a pattern. Defaults to 0,0.")
+
+	flag.StringVar(
+		&params.OutputFormat,
+		"output-format",
+		"pgm",
+		"Specify the format of saved images: pgm, pgm-plain, pbm, pbm-plain, rle, cells or lif. Defaults to pgm.")
+
+	flag.StringVar(
+		&params.Broker,
+		"broker",
+		"",
+		"Specify the address of a broker to run the turns on, e.g. 127.0.0.1:8030. Defaults to running locally.")
+
+	flag.StringVar(
+		&params.Checkpoint,
+		"checkpoint",
+		"",
+		"Specify a file to checkpoint the run to periodically, on 's' and on quitting. Defaults to no checkpoints.")
+
+	flag.DurationVar(
+		&params.CheckpointEvery,
+		"checkpoint-every",
+		time.Minute,
+		"Specify how often to checkpoint. Defaults to 1m.")
+
+	flag.StringVar(
+		&params.Resume,
+		"resume",
+		"",
+		"Specify a checkpoint to resume a run from, with the size, rule and topology it was taken with.")
+
+	flag.BoolVar(
+		&params.DetectCycles,
+		"detect-cycles",
+		false,
+		"Look for the world repeating itself and report the cycle it falls into.")
+
+	flag.BoolVar(
+		&params.SkipCycles,
+		"skip-cycles",
+		false,
+		"Detect cycles and skip straight to the final turn once the world is in one.")
+
+	flag.IntVar(
+		&params.TPS,
+		"tps",
+		0,
+		"Specify the most turns to run a second, which '+' and '-' double and halve and '0' lifts. Defaults to unlimited.")
+
+	flag.IntVar(
+		&params.History,
+		"history",
+		100,
+		"Specify how many steps to remember for stepping back with 'b' and rewinding with 'r'. -1 remembers none.")
+
+	workers := flag.String(
+		"workers",
+		"",
+		"Specify the comma-separated addresses of worker servers to run the strips on as a cluster. Defaults to running locally.")
+
+	daemon := flag.String(
+		"daemon",
+		"",
+		"Run the simulation headless in the background, letting controllers attach to it on this unix socket.")
+
+	attach := flag.String(
+		"attach",
+		"",
+		"Attach to the daemon listening on this unix socket instead of running a simulation. 'q' detaches.")
+
 	headless := flag.Bool(
 		"headless",
 		false,
-		"Disable the SDL window for running in a headless environment.")
+		"Disable the SDL window for running in a headless environment. Keys typed in the terminal, SIGUSR1 (save) and SIGUSR2 (pause) still control the run.")
+
+	render := flag.String(
+		"render",
+		"sdl",
+		"Specify how to show the world: sdl, term to draw it in the terminal, or none. Defaults to sdl, or none with -headless.")
 
 	flag.Parse()
 
+	if *headless && *render == "sdl" {
+		*render = "none"
+	}
+	if *render != "sdl" && *render != "term" && *render != "none" {
+		fmt.Println("invalid renderer", *render, "expected sdl, term or none")
+		os.Exit(1)
+	}
+
+	keyPresses := make(chan rune, 10)
+	edits := make(chan gol.CellEdit, 100)
+	events := make(chan gol.Event, 1000)
+
+	if *attach != "" {
+		params, err := gol.Attach(*attach, events, keyPresses, edits)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		fmt.Printf("%-10v %v\n", "Attached", *attach)
+		go sigterm(keyPresses)
+		sigusr(keyPresses)
+		runUI(params, events, keyPresses, edits, *render)
+		return
+	}
+
+	rule, err := gol.ParseRule(params.Rule)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	params.Topology, err = gol.ParseTopology(*topology)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	if _, err = fmt.Sscanf(*offset, "%d,%d", &params.OffsetX, &params.OffsetY); err != nil {
+		fmt.Println("invalid offset", *offset, "expected x,y")
+		os.Exit(1)
+	}
+
+	if *workers != "" {
+		params.Workers = strings.Split(*workers, ",")
+	}
+
+	if params.Input == "" && params.Resume == "" {
+		if params.ImageWidth == 0 {
+			params.ImageWidth = 512
+		}
+		if params.ImageHeight == 0 {
+			params.ImageHeight = 512
+		}
+	}
+	params, err = gol.ResolveParams(params)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	// A resumed run carries on under the rule of its checkpoint, and a pattern may bring its own rule.
+	rule, _ = gol.ParseRule(params.Rule)
+
 	fmt.Printf("%-10v %v\n", "Threads", params.Threads)
 	fmt.Printf("%-10v %v\n", "Width", params.ImageWidth)
 	fmt.Printf("%-10v %v\n", "Height", params.ImageHeight)
 	fmt.Printf("%-10v %v\n", "Turns", params.Turns)
-
-	keyPresses := make(chan rune, 10)
-	events := make(chan gol.Event, 1000)
+	fmt.Printf("%-10v %v\n", "Rule", rule)
+	fmt.Printf("%-10v %v\n", "Topology", params.Topology)
+	fmt.Printf("%-10v %v\n", "Engine", params.Engine)
+	if params.Resume != "" {
+		fmt.Printf("%-10v %v\n", "Resume", params.Resume)
+	}
+	if params.Checkpoint != "" {
+		fmt.Printf("%-10v %v every %v\n", "Checkpoint", params.Checkpoint, params.CheckpointEvery)
+	}
+	if params.TPS > 0 {
+		fmt.Printf("%-10v %v\n", "TPS", params.TPS)
+	}
+	if params.SkipCycles {
+		fmt.Printf("%-10v %v\n", "Cycles", "detect and skip")
+	} else if params.DetectCycles {
+		fmt.Printf("%-10v %v\n", "Cycles", "detect")
+	}
+	if params.Broker != "" {
+		fmt.Printf("%-10v %v\n", "Broker", params.Broker)
+	}
+	if len(params.Workers) > 0 {
+		fmt.Printf("%-10v %v\n", "Workers", strings.Join(params.Workers, ", "))
+	}
 
 	go sigterm(keyPresses)
+	sigusr(keyPresses)
 
-	go gol.Run(params, events, keyPresses)
-	if !(*headless) {
-		sdl.Run(params, events, keyPresses)
-	} else {
+	if *daemon != "" {
+		listener, err := net.Listen("unix", *daemon)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		fmt.Printf("%-10v %v\n", "Daemon", *daemon)
+		if err = gol.ServeDaemon(listener, params, keyPresses); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- gol.RunEdits(params, events, keyPresses, edits)
+	}()
+	runUI(params, events, keyPresses, edits, *render)
+	if err := <-runErr; err != nil {
+		os.Exit(1)
+	}
+}
+
+// runUI shows the events in an SDL window, draws them in the terminal or just prints them, as render says,
+// until the simulation ends. Cells clicked in the window are sent on edits, and keys pressed in the terminal
+// on keyPresses when there is no window.
+func runUI(params gol.Params, events <-chan gol.Event, keyPresses chan<- rune, edits chan<- gol.CellEdit, render string) {
+	switch render {
+	case "term":
+		term.Run(params, events, keyPresses, os.Stdin, os.Stdout)
+	case "none":
+		restore := term.ForwardKeys(os.Stdin, keyPresses)
+		defer restore()
 		sdl.RunHeadless(events)
+	default:
+		sdl.Run(params, events, keyPresses, edits)
 	}
 }
 
+// sigterm quits when the process is terminated, which saves the world and, with -checkpoint, checkpoints the run.
 func sigterm(keyPresses chan<- rune) {
 	sigterm := make(chan os.Signal, 1)
 	signal.Notify(sigterm, syscall.SIGTERM, syscall.SIGINT)
 	<-sigterm
 	keyPresses <- 'q'
 }
+
+// sigusr saves the world on SIGUSR1 and pauses or resumes on SIGUSR2, for controlling runs in the background.
+// The signals are handled from when it returns.
+func sigusr(keyPresses chan<- rune) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGUSR2)
+	go func() {
+		for sig := range signals {
+			if sig == syscall.SIGUSR1 {
+				keyPresses <- 's'
+			} else {
+				keyPresses <- 'p'
+			}
+		}
+	}()
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// TestPaths tests loading any input file, inferring the size from its header, and saving into any directory.
+func TestPaths(t *testing.T) {
+	t.Run("pgm", testPathsPgm)
+	t.Run("pattern", testPathsPattern)
+	t.Run("no extension", testPathsNoExtension)
+}
+
+func testPathsPgm(t *testing.T) {
+	outDir := filepath.Join(t.TempDir(), "snapshots")
+	p := gol.Params{Turns: 100, Threads: 4, Input: "images/64x64.pgm", OutDir: outDir}
+
+	resolved, err := gol.ResolveParams(p)
+	util.Check(err)
+	assert(t, resolved.ImageWidth == 64 && resolved.ImageHeight == 64,
+		"Size should be inferred as 64x64, not %vx%v", resolved.ImageWidth, resolved.ImageHeight)
+
+	expectedAlive := readAliveCells("check/images/64x64x100.pgm", 64, 64)
+	assertEqualBoard(t, runFinal(p), expectedAlive, resolved)
+	assertEqualBoard(t, readAliveCells(filepath.Join(outDir, "64x64x100.pgm"), 64, 64), expectedAlive, resolved)
+}
+
+func testPathsPattern(t *testing.T) {
+	glider := writePattern(t, "glider.cells", ".O\n..O\nOOO\n")
+	p := gol.Params{Turns: 32, Threads: 2, Input: glider, OffsetX: 5, OffsetY: 5, OutDir: t.TempDir()}
+
+	resolved, err := gol.ResolveParams(p)
+	util.Check(err)
+	assert(t, resolved.ImageWidth == 8 && resolved.ImageHeight == 8,
+		"Size should be inferred as 8x8, not %vx%v", resolved.ImageWidth, resolved.ImageHeight)
+
+	// A glider returns to its starting cells after travelling all the way around an 8x8 torus.
+	expected := []util.Cell{{X: 6, Y: 5}, {X: 7, Y: 6}, {X: 5, Y: 7}, {X: 6, Y: 7}, {X: 7, Y: 7}}
+	assertEqualBoard(t, runFinal(p), expected, resolved)
+}
+
+// testPathsNoExtension loads an image from a path without an extension exactly as given.
+func testPathsNoExtension(t *testing.T) {
+	image, err := os.ReadFile("images/16x16.pgm")
+	util.Check(err)
+	world := filepath.Join(t.TempDir(), "world")
+	util.Check(os.WriteFile(world, image, 0644))
+	p := gol.Params{Turns: 100, Threads: 4, Input: world, OutDir: t.TempDir()}
+
+	resolved, err := gol.ResolveParams(p)
+	util.Check(err)
+	assert(t, resolved.ImageWidth == 16 && resolved.ImageHeight == 16,
+		"Size should be inferred as 16x16, not %vx%v", resolved.ImageWidth, resolved.ImageHeight)
+	assertEqualBoard(t, runFinal(p), readAliveCells("check/images/16x16x100.pgm", 16, 16), resolved)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// TestPatternFormats tests reading and writing plaintext and Life 1.06 patterns.
+func TestPatternFormats(t *testing.T) {
+	t.Run("cells", testPatternCells)
+	t.Run("lif", testPatternLife106)
+	t.Run("output", testPatternOutput)
+}
+
+// glider4 is the glider read by these tests at offset 5,7 after 4 turns.
+var glider4 = []util.Cell{{X: 7, Y: 8}, {X: 8, Y: 9}, {X: 6, Y: 10}, {X: 7, Y: 10}, {X: 8, Y: 10}}
+
+func testPatternCells(t *testing.T) {
+	glider := writePattern(t, "glider.cells", "!Name: Glider\n!\n.O\n..O\nOOO\n")
+	p := gol.Params{Turns: 4, Threads: 4, ImageWidth: 16, ImageHeight: 16, Input: glider, OffsetX: 5, OffsetY: 7}
+	assertEqualBoard(t, runFinal(p), glider4, p)
+}
+
+func testPatternLife106(t *testing.T) {
+	glider := writePattern(t, "glider.lif", "#Life 1.06\n0 -1\n1 0\n-1 1\n0 1\n1 1\n")
+	p := gol.Params{Turns: 4, Threads: 4, ImageWidth: 16, ImageHeight: 16, Input: glider, OffsetX: 6, OffsetY: 8}
+	assertEqualBoard(t, runFinal(p), glider4, p)
+}
+
+func testPatternOutput(t *testing.T) {
+	for _, format := range []string{"cells", "lif"} {
+		t.Run(format, func(t *testing.T) {
+			emptyOutFolder()
+			p := gol.Params{Turns: 100, Threads: 4, ImageWidth: 16, ImageHeight: 16, OutputFormat: format}
+			expectedAlive := readAliveCells("check/images/16x16x100.pgm", p.ImageWidth, p.ImageHeight)
+			assertEqualBoard(t, runFinal(p), expectedAlive, p)
+
+			reloaded := gol.Params{Turns: 0, Threads: 1, ImageWidth: 16, ImageHeight: 16, Input: "out/16x16x100." + format}
+			assertEqualBoard(t, runFinal(reloaded), expectedAlive, reloaded)
+		})
+	}
+}
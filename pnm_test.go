@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// TestPnm tests reading pbm and pgm images in their plain and binary forms, and writing them.
+func TestPnm(t *testing.T) {
+	t.Run("read", testPnmRead)
+	t.Run("output", testPnmOutput)
+}
+
+// blinker4 is a vertical blinker in the second column of a 4x4 image.
+var blinker4 = []util.Cell{{X: 1, Y: 0}, {X: 1, Y: 1}, {X: 1, Y: 2}}
+
+func testPnmRead(t *testing.T) {
+	images := map[string]string{
+		// Dead pixels that are whitespace bytes must not be taken for separators.
+		"binary.pgm": "P5\n# A comment\n4 4 # another\n255\n" +
+			"\x09\xff\x0a\x0d" + "\x20\xc8\x00\x00" + "\x7f\x80\x20\x20" + "\x00\x00\x00\x00",
+		"wide.pgm": "P5 4 4 65535\n" +
+			"\x00\x00\xff\xff\x00\x00\x00\x00" + "\x00\x00\x80\x00\x00\x00\x00\x00" +
+			"\x7f\xff\x80\x00\x00\x00\x00\x00" + "\x00\x00\x00\x00\x00\x00\x00\x00",
+		"plain.pgm":  "P2\n# maxval 15\n4 4\n15\n0 15 0 0\n7 8 0 0\n0 12 0 7\n0 0 0 0\n",
+		"binary.pbm": "P4\n4 4\n\x40\x40\x40\x00",
+		"plain.pbm":  "P1\n# digits need not be separated\n4 4\n0100\n0 1 0 0\n0100 0000\n",
+	}
+	for name, contents := range images {
+		t.Run(name, func(t *testing.T) {
+			p := gol.Params{Turns: 0, Threads: 1, ImageWidth: 4, ImageHeight: 4, Input: writePattern(t, name, contents)}
+			assertEqualBoard(t, runFinal(p), blinker4, p)
+		})
+	}
+}
+
+func testPnmOutput(t *testing.T) {
+	for format, extension := range map[string]string{"pgm-plain": ".pgm", "pbm": ".pbm", "pbm-plain": ".pbm"} {
+		t.Run(format, func(t *testing.T) {
+			p := gol.Params{Turns: 100, Threads: 4, ImageWidth: 16, ImageHeight: 16, OutputFormat: format, OutDir: t.TempDir()}
+			expectedAlive := readAliveCells("check/images/16x16x100.pgm", p.ImageWidth, p.ImageHeight)
+			assertEqualBoard(t, runFinal(p), expectedAlive, p)
+
+			reloaded := gol.Params{Turns: 0, Threads: 1, ImageWidth: 16, ImageHeight: 16, Input: p.OutDir + "/16x16x100" + extension}
+			assertEqualBoard(t, runFinal(reloaded), expectedAlive, reloaded)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// TestRewind tests rewinding a running world with 'r', saving the turn it went back to and carrying on from there.
+func TestRewind(t *testing.T) {
+	p := gol.Params{Turns: 100000000, Threads: 4, ImageWidth: 64, ImageHeight: 64, History: 20, OutDir: t.TempDir()}
+	keyPresses := make(chan rune, 10)
+	events := make(chan gol.Event, 1000)
+	go gol.Run(p, events, keyPresses)
+
+	initial := readAliveCells("images/64x64.pgm", 64, 64)
+	reference := func(turns int) []util.Cell {
+		q := p
+		q.Turns = turns
+		return referenceRun(initial, q)
+	}
+
+	world := make(map[util.Cell]bool)
+	cells := func() []util.Cell {
+		var alive []util.Cell
+		for cell, isAlive := range world {
+			if isAlive {
+				alive = append(alive, cell)
+			}
+		}
+		return alive
+	}
+
+	pausedAt, rewoundTo, resumedAt := -1, -1, -1
+	saved := ""
+	for event := range events {
+		switch e := event.(type) {
+		case gol.CellFlipped:
+			world[e.Cell] = !world[e.Cell]
+		case gol.CellsFlipped:
+			for _, cell := range e.Cells {
+				world[cell] = !world[cell]
+			}
+		case gol.TurnComplete:
+			switch {
+			case pausedAt < 0 && e.CompletedTurns == 50:
+				keyPresses <- 'r'
+			case pausedAt >= 0 && rewoundTo < 0:
+				rewoundTo = e.CompletedTurns
+				assertEqualBoard(t, cells(), reference(rewoundTo), p)
+				keyPresses <- 's'
+			case resumedAt >= 0 && e.CompletedTurns == rewoundTo+5:
+				keyPresses <- 'q'
+			}
+		case gol.StateChange:
+			switch {
+			case e.NewState == gol.Paused:
+				pausedAt = e.CompletedTurns
+			case e.NewState == gol.Executing && rewoundTo >= 0:
+				resumedAt = e.CompletedTurns
+			}
+		case gol.ImageOutputComplete:
+			if saved == "" {
+				saved = e.Filename
+				keyPresses <- 'p'
+			}
+		case gol.FinalTurnComplete:
+			assertEqualBoard(t, e.Alive, reference(e.CompletedTurns), p)
+		}
+	}
+
+	assert(t, pausedAt >= 50, "Rewinding should pause the run after turn 50, not at %v", pausedAt)
+	assert(t, rewoundTo == pausedAt-20, "Rewinding from turn %v should go back 20 turns, not to %v", pausedAt, rewoundTo)
+	assert(t, resumedAt == rewoundTo, "The run should resume from turn %v, not %v", rewoundTo, resumedAt)
+	expectedName := fmt.Sprintf("64x64x%v", rewoundTo)
+	assert(t, saved == expectedName, "The rewound turn should be saved as %v, not %v", expectedName, saved)
+	if saved == expectedName {
+		assertEqualBoard(t, readAliveCells(filepath.Join(p.OutDir, saved+".pgm"), 64, 64), reference(rewoundTo), p)
+	}
+}
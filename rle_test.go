@@ -0,0 +1,71 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// TestRle tests reading rle patterns at an offset, honouring their rule, and writing rle output.
+func TestRle(t *testing.T) {
+	t.Run("glider", testRleGlider)
+	t.Run("rule", testRleRule)
+	t.Run("output", testRleOutput)
+}
+
+// runFinal runs the Game of Life and returns the alive cells reported by FinalTurnComplete.
+func runFinal(p gol.Params) []util.Cell {
+	events := make(chan gol.Event)
+	go gol.Run(p, events, nil)
+	var cells []util.Cell
+	for event := range events {
+		switch e := event.(type) {
+		case gol.FinalTurnComplete:
+			cells = e.Alive
+		}
+	}
+	return cells
+}
+
+func writePattern(t *testing.T, name, contents string) string {
+	path := filepath.Join(t.TempDir(), name)
+	err := os.WriteFile(path, []byte(contents), 0644)
+	util.Check(err)
+	return path
+}
+
+func testRleGlider(t *testing.T) {
+	glider := writePattern(t, "glider.rle", "#N Glider\n#C A comment\nx = 3, y = 3, rule = B3/S23\nbob$2bo$3o!\n")
+	p := gol.Params{Turns: 4, Threads: 4, ImageWidth: 16, ImageHeight: 16, Input: glider, OffsetX: 5, OffsetY: 7}
+	expected := []util.Cell{{X: 7, Y: 8}, {X: 8, Y: 9}, {X: 6, Y: 10}, {X: 7, Y: 10}, {X: 8, Y: 10}}
+	assertEqualBoard(t, runFinal(p), expected, p)
+}
+
+func testRleRule(t *testing.T) {
+	line := writePattern(t, "line.rle", "x = 3, y = 1, rule = B2/S\n3o!\n")
+	p := gol.Params{Turns: 1, Threads: 2, ImageWidth: 16, ImageHeight: 16, Input: line, OffsetX: 5, OffsetY: 5}
+	seeds := []util.Cell{{X: 5, Y: 4}, {X: 7, Y: 4}, {X: 5, Y: 6}, {X: 7, Y: 6}}
+	assertEqualBoard(t, runFinal(p), seeds, p)
+
+	// The rule in use is resolved from the header, so that every UI can show it.
+	resolved, err := gol.ResolveParams(p)
+	util.Check(err)
+	assert(t, resolved.Rule == "B2/S", "The rule should be resolved from the header as B2/S, not %q", resolved.Rule)
+
+	p.Rule = "B3/S23"
+	blinker := []util.Cell{{X: 6, Y: 4}, {X: 6, Y: 5}, {X: 6, Y: 6}}
+	assertEqualBoard(t, runFinal(p), blinker, p)
+}
+
+func testRleOutput(t *testing.T) {
+	emptyOutFolder()
+	p := gol.Params{Turns: 100, Threads: 4, ImageWidth: 16, ImageHeight: 16, OutputFormat: "rle"}
+	expectedAlive := readAliveCells("check/images/16x16x100.pgm", p.ImageWidth, p.ImageHeight)
+	assertEqualBoard(t, runFinal(p), expectedAlive, p)
+
+	reloaded := gol.Params{Turns: 0, Threads: 1, ImageWidth: 16, ImageHeight: 16, Input: "out/16x16x100.rle"}
+	assertEqualBoard(t, runFinal(reloaded), expectedAlive, reloaded)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// TestRule tests rulestring parsing and that equivalent Conway rulestrings reproduce the check images.
+func TestRule(t *testing.T) {
+	t.Run("parse", testRuleParse)
+	t.Run("conway", testRuleConway)
+}
+
+func testRuleParse(t *testing.T) {
+	valid := map[string]string{
+		"":             "B3/S23",
+		"B3/S23":       "B3/S23",
+		"b36/s23":      "B36/S23",
+		"B3678/S34678": "B3678/S34678",
+		"B2/S":         "B2/S",
+		"S23/B3":       "B3/S23",
+		"B3S23":        "B3/S23",
+		"23/3":         "B3/S23",
+		"23/36":        "B36/S23",
+		"/2":           "B2/S",
+	}
+	for rulestring, expected := range valid {
+		rule, err := gol.ParseRule(rulestring)
+		if err != nil {
+			t.Errorf("ERROR: %q should be a valid rule, got %v", rulestring, err)
+		} else if rule.String() != expected {
+			t.Errorf("ERROR: %q should parse to %v, got %v", rulestring, expected, rule)
+		}
+	}
+
+	for _, rulestring := range []string{"B9/S23", "S23", "B3/B4", "3", "23/3/1", "B3/S2x", "Life"} {
+		if _, err := gol.ParseRule(rulestring); err == nil {
+			t.Errorf("ERROR: %q should be an invalid rule", rulestring)
+		}
+	}
+}
+
+func testRuleConway(t *testing.T) {
+	for _, rulestring := range []string{"B3/S23", "23/3", "b3s23"} {
+		p := gol.Params{ImageWidth: 16, ImageHeight: 16, Turns: 100, Threads: 4, Rule: rulestring}
+		expectedAlive := readAliveCells(
+			"check/images/"+fmt.Sprintf("%vx%vx%v.pgm", p.ImageWidth, p.ImageHeight, p.Turns),
+			p.ImageWidth,
+			p.ImageHeight,
+		)
+		t.Run(rulestring, func(t *testing.T) {
+			events := make(chan gol.Event)
+			go gol.Run(p, events, nil)
+			var cells []util.Cell
+			for event := range events {
+				switch e := event.(type) {
+				case gol.FinalTurnComplete:
+					cells = e.Alive
+				}
+			}
+			assertEqualBoard(t, cells, expectedAlive, p)
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package sdl
+
+// colourMode is how the window colours the cells.
+type colourMode int
+
+const (
+	// plainColours draws live cells white.
+	plainColours colourMode = iota
+	// ageColours colours live cells by how many turns they have been alive, from yellow to blue.
+	ageColours
+	// heatColours draws live cells white and cells that died recently glowing red as they cool.
+	heatColours
+	// changeColours draws the cells born this turn green, those that died this turn red and the rest grey.
+	changeColours
+	colourModes
+)
+
+func (m colourMode) String() string {
+	switch m {
+	case plainColours:
+		return "Plain"
+	case ageColours:
+		return "Age"
+	case heatColours:
+		return "Heat"
+	case changeColours:
+		return "Births and deaths"
+	default:
+		return "Unknown"
+	}
+}
+
+// heatTurns is how many turns a dead cell takes to cool to black.
+const heatTurns = 32
+
+type colour struct {
+	r, g, b byte
+}
+
+var (
+	black = colour{0, 0, 0}
+	white = colour{0xFF, 0xFF, 0xFF}
+	grey  = colour{0x60, 0x60, 0x60}
+	green = colour{0x20, 0xE0, 0x40}
+	red   = colour{0xF0, 0x30, 0x20}
+)
+
+// ageGradient runs from the colour of a newborn cell to that of a cell alive for 2^(len-1)-1 turns or more,
+// each colour being twice as old as the one before.
+var ageGradient = []colour{
+	{0xFF, 0xF0, 0x60},
+	{0xFF, 0xB0, 0x30},
+	{0xF0, 0x60, 0x20},
+	{0xD0, 0x20, 0x50},
+	{0x90, 0x20, 0xA0},
+	{0x50, 0x30, 0xD0},
+	{0x20, 0x50, 0xF0},
+	{0x10, 0x80, 0xFF},
+	{0x10, 0xB0, 0xFF},
+}
+
+// colourOf returns the colour of a cell that has been alive or dead for age turns.
+// A cell that has never flipped has been so since turn 0.
+func (m colourMode) colourOf(alive bool, age int) colour {
+	if age < 0 {
+		// Rewinding can leave cells changed after the turn shown.
+		age = 0
+	}
+	switch m {
+	case ageColours:
+		if !alive {
+			return black
+		}
+		return ageColour(age)
+	case heatColours:
+		if alive {
+			return white
+		}
+		if age >= heatTurns {
+			return black
+		}
+		return fade(red, heatTurns-age, heatTurns)
+	case changeColours:
+		switch {
+		case age == 0 && alive:
+			return green
+		case age == 0:
+			return red
+		case alive:
+			return grey
+		}
+		return black
+	default:
+		if alive {
+			return white
+		}
+		return black
+	}
+}
+
+// ageColour blends between the two colours of the gradient either side of age.
+func ageColour(age int) colour {
+	step, from := 0, 0
+	for to := 1; age+1 >= 2*to && step < len(ageGradient)-1; to *= 2 {
+		step, from = step+1, 2*to-1
+	}
+	if step == len(ageGradient)-1 {
+		return ageGradient[step]
+	}
+	return blend(ageGradient[step], ageGradient[step+1], age-from, from+1)
+}
+
+// blend mixes a into b in the ratio n:d.
+func blend(a, b colour, n, d int) colour {
+	mix := func(x, y byte) byte {
+		return byte((int(x)*(d-n) + int(y)*n) / d)
+	}
+	return colour{mix(a.r, b.r), mix(a.g, b.g), mix(a.b, b.b)}
+}
+
+// fade dims c to n/d of its brightness.
+func fade(c colour, n, d int) colour {
+	return blend(black, c, n, d)
+}
@@ -0,0 +1,107 @@
+package sdl
+
+import (
+	"math"
+	"testing"
+)
+
+// TestColourOf tests the colour of live and dead cells of each age in each colour mode.
+func TestColourOf(t *testing.T) {
+	last := ageGradient[len(ageGradient)-1]
+	tests := []struct {
+		name     string
+		mode     colourMode
+		alive    bool
+		age      int
+		expected colour
+	}{
+		{"plain alive", plainColours, true, 5, white},
+		{"plain dead", plainColours, false, 0, black},
+
+		{"newborn", ageColours, true, 0, ageGradient[0]},
+		{"rewound past birth", ageColours, true, -3, ageGradient[0]},
+		{"one turn", ageColours, true, 1, ageGradient[1]},
+		{"between one and three turns", ageColours, true, 2, blend(ageGradient[1], ageGradient[2], 1, 2)},
+		{"three turns", ageColours, true, 3, ageGradient[2]},
+		{"between seven and fifteen turns", ageColours, true, 10, blend(ageGradient[3], ageGradient[4], 3, 8)},
+		{"fifteen turns", ageColours, true, 15, ageGradient[4]},
+		{"last before saturating", ageColours, true, 254, blend(ageGradient[7], ageGradient[8], 127, 128)},
+		{"saturated", ageColours, true, 255, last},
+		{"long saturated", ageColours, true, 1000000, last},
+		{"age of a dead cell", ageColours, false, 3, black},
+
+		{"alive in the heat map", heatColours, true, 40, white},
+		{"just died", heatColours, false, 0, red},
+		{"cooling", heatColours, false, heatTurns / 2, fade(red, heatTurns/2, heatTurns)},
+		{"nearly cold", heatColours, false, heatTurns - 1, fade(red, 1, heatTurns)},
+		{"cold", heatColours, false, heatTurns, black},
+		{"long dead", heatColours, false, 1000, black},
+
+		{"born this turn", changeColours, true, 0, green},
+		{"died this turn", changeColours, false, 0, red},
+		{"survived", changeColours, true, 1, grey},
+		{"stayed dead", changeColours, false, 1, black},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if c := test.mode.colourOf(test.alive, test.age); c != test.expected {
+				t.Errorf("Expected %v, not %v", test.expected, c)
+			}
+		})
+	}
+}
+
+// TestFade tests that a dead cell cools a little every turn until it is black.
+func TestFade(t *testing.T) {
+	previous := heatColours.colourOf(false, 0)
+	for age := 1; age <= heatTurns; age++ {
+		c := heatColours.colourOf(false, age)
+		if c.r >= previous.r {
+			t.Fatalf("A cell dead for %v turns should be cooler than %v, not %v", age, previous, c)
+		}
+		previous = c
+	}
+	if previous != black {
+		t.Errorf("A cell dead for %v turns should be black, not %v", heatTurns, previous)
+	}
+}
+
+// TestPaint tests that the window colours each cell from the turn it last flipped at.
+func TestPaint(t *testing.T) {
+	w := &Window{
+		Width:   3,
+		Height:  1,
+		pixels:  make([]byte, 3*4),
+		alive:   make([]bool, 3),
+		changed: make([]int, 3),
+		mode:    changeColours,
+	}
+	w.FlipPixel(0, 0)
+	w.FlipPixel(1, 0)
+	w.SetTurn(5)
+	w.FlipPixel(1, 0)
+	w.FlipPixel(2, 0)
+	w.paint()
+
+	for x, expected := range []colour{grey, red, green} {
+		pixel := w.pixels[4*x : 4*x+4]
+		if c := (colour{r: pixel[2], g: pixel[1], b: pixel[0]}); c != expected || pixel[3] != 0xFF {
+			t.Errorf("Cell %v should be %v, not %v", x, expected, pixel)
+		}
+	}
+	if w.CountPixels() != 2 {
+		t.Errorf("2 cells are alive, not %v", w.CountPixels())
+	}
+
+	// Long runs pass turns that do not fit in 32 bits.
+	w.SetTurn(math.MaxInt32 + 10)
+	w.FlipPixel(0, 0)
+	w.FlipPixel(1, 0)
+	w.paint()
+	for x, expected := range []colour{red, green, grey} {
+		pixel := w.pixels[4*x : 4*x+4]
+		if c := (colour{r: pixel[2], g: pixel[1], b: pixel[0]}); c != expected {
+			t.Errorf("Cell %v should be %v past turn %v, not %v", x, expected, math.MaxInt32, pixel)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package sdl
+
+import (
+	"strings"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+)
+
+// TestGlyphs tests that the font can draw everything the HUD shows.
+func TestGlyphs(t *testing.T) {
+	w := &Window{alive: make([]bool, 4), changed: make([]int, 4)}
+	h := hud{turn: 1234567890, rate: 98765, rule: "B3678/S34678", threads: 16}
+	text := "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789/.: "
+	for _, state := range []gol.State{gol.Paused, gol.Executing, gol.Quitting} {
+		h.state = state
+		for w.mode = 0; w.mode < colourModes; w.mode++ {
+			text += strings.Join(h.lines(w), "")
+		}
+	}
+	for _, r := range strings.ToUpper(text) {
+		if _, ok := glyphs[r]; !ok {
+			t.Errorf("%q has no glyph", r)
+		}
+	}
+	for r, glyph := range glyphs {
+		for _, row := range glyph {
+			if len(row) != glyphWidth {
+				t.Errorf("The glyph of %q has a row %q that is not %v pixels wide", r, row, glyphWidth)
+			}
+		}
+	}
+}
+
+// TestTextPixels tests where the pixels of each character of a line are placed.
+func TestTextPixels(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		x, y     int32
+		expected [][2]int32
+	}{
+		{"i", "i", 10, 20, [][2]int32{
+			{11, 20}, {12, 20}, {13, 20},
+			{12, 21}, {12, 22}, {12, 23}, {12, 24}, {12, 25},
+			{11, 26}, {12, 26}, {13, 26},
+		}},
+		{"two characters", "-.", 0, 0, [][2]int32{
+			{0, 3}, {1, 3}, {2, 3}, {3, 3}, {4, 3},
+			{7, 5}, {8, 5}, {7, 6}, {8, 6},
+		}},
+		{"space", " ", 5, 5, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pixels := textPixels(test.text, test.x, test.y)
+			if len(pixels) != len(test.expected) {
+				t.Fatalf("Expected the pixels %v, not %v", test.expected, pixels)
+			}
+			for i := range pixels {
+				if pixels[i] != test.expected[i] {
+					t.Fatalf("Expected the pixels %v, not %v", test.expected, pixels)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package sdl
+
+import (
+	"fmt"
+
+	"github.com/veandco/go-sdl2/sdl"
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// Each pixel of the HUD font is drawn hudScale window pixels square, hudMargin pixels from the edges of its backdrop.
+const (
+	hudScale  = 2
+	hudMargin = 6
+)
+
+// hud is the heads-up display of the run drawn over the top left of the board.
+type hud struct {
+	shown   bool
+	turn    int
+	rate    int // Turns per second, as last printed.
+	state   gol.State
+	rule    string
+	threads int
+}
+
+// lines returns the text of the HUD for the cells shown in w.
+func (h *hud) lines(w *Window) []string {
+	return []string{
+		fmt.Sprintf("Turn %v", h.turn),
+		fmt.Sprintf("Alive %v", w.CountPixels()),
+		fmt.Sprintf("%v turns/s", h.rate),
+		h.state.String(),
+		fmt.Sprintf("Rule %v", h.rule),
+		fmt.Sprintf("Threads %v", h.threads),
+		fmt.Sprintf("Colours %v", w.mode),
+	}
+}
+
+// SetHUD sets the lines of text drawn over the board each frame, or hides the HUD if there are none.
+func (w *Window) SetHUD(lines []string) {
+	w.hud = lines
+}
+
+// drawHUD draws the HUD on a translucent backdrop.
+func (w *Window) drawHUD() {
+	if len(w.hud) == 0 {
+		return
+	}
+	var rects []sdl.Rect
+	width := 0
+	for i, line := range w.hud {
+		if len(line) > width {
+			width = len(line)
+		}
+		for _, pixel := range textPixels(line, 0, int32(i*(glyphHeight+2))) {
+			rects = append(rects, sdl.Rect{
+				X: hudMargin + pixel[0]*hudScale,
+				Y: hudMargin + pixel[1]*hudScale,
+				W: hudScale,
+				H: hudScale,
+			})
+		}
+	}
+	backdrop := sdl.Rect{
+		W: int32(width*(glyphWidth+1)*hudScale + 2*hudMargin),
+		H: int32(len(w.hud)*(glyphHeight+2)*hudScale + 2*hudMargin),
+	}
+
+	err := w.renderer.SetDrawBlendMode(sdl.BLENDMODE_BLEND)
+	util.Check(err)
+	err = w.renderer.SetDrawColor(0, 0, 0, 0xA0)
+	util.Check(err)
+	err = w.renderer.FillRect(&backdrop)
+	util.Check(err)
+	err = w.renderer.SetDrawColor(0xFF, 0xFF, 0xFF, 0xFF)
+	util.Check(err)
+	if len(rects) > 0 {
+		err = w.renderer.FillRects(rects)
+		util.Check(err)
+	}
+	// The renderer clears to its draw colour.
+	err = w.renderer.SetDrawColor(0, 0, 0, 0xFF)
+	util.Check(err)
+}
@@ -10,19 +10,28 @@ import (
 
 const FPS = 60
 
-func Run(p gol.Params, events <-chan gol.Event, keyPresses chan<- rune) {
+// Run shows the events in a window until the simulation quits, sending key presses on keyPresses
+// and the cells clicked or dragged over on edits.
+func Run(p gol.Params, events <-chan gol.Event, keyPresses chan<- rune, edits chan<- gol.CellEdit) {
 	w := NewWindow(int32(p.ImageWidth), int32(p.ImageHeight))
 	defer w.Destroy()
+	status := hud{shown: true, state: gol.Executing, threads: p.Threads}
+	if rule, err := gol.ParseRule(p.Rule); err == nil {
+		w.SetTitle(fmt.Sprintf("GOL GUI - %v", rule))
+		status.rule = rule.String()
+	}
 	dirty := false
 	refreshTicker := time.NewTicker(time.Second / time.Duration(FPS))
 	avgTurns := util.NewAvgTurns()
+	mouse := painter{edits: edits}
+	panning := false
 
 sdl:
 	for {
 		select {
 		case <-refreshTicker.C:
-			event := w.PollEvent()
-			if event != nil {
+			// Dragging the mouse queues many events a frame, so all of them are handled.
+			for event := w.PollEvent(); event != nil; event = w.PollEvent() {
 				switch e := event.(type) {
 				case *sdl.QuitEvent:
 					keyPresses <- 'q'
@@ -38,10 +47,67 @@ sdl:
 						keyPresses <- 'q'
 					case sdl.K_k:
 						keyPresses <- 'k'
+					case sdl.K_n:
+						keyPresses <- 'n'
+					case sdl.K_b:
+						keyPresses <- 'b'
+					case sdl.K_r:
+						keyPresses <- 'r'
+					case sdl.K_PLUS, sdl.K_EQUALS, sdl.K_KP_PLUS:
+						keyPresses <- '+'
+					case sdl.K_MINUS, sdl.K_KP_MINUS:
+						keyPresses <- '-'
+					case sdl.K_0, sdl.K_KP_0:
+						keyPresses <- '0'
+					// The view is changed by the window alone.
+					case sdl.K_LEFTBRACKET:
+						w.ZoomCentre(false)
+					case sdl.K_RIGHTBRACKET:
+						w.ZoomCentre(true)
+					case sdl.K_f:
+						w.Fit()
+					case sdl.K_c:
+						w.CycleColours()
+					case sdl.K_h:
+						status.shown = !status.shown
+					case sdl.K_LEFT:
+						w.Pan(-1, 0)
+					case sdl.K_RIGHT:
+						w.Pan(1, 0)
+					case sdl.K_UP:
+						w.Pan(0, -1)
+					case sdl.K_DOWN:
+						w.Pan(0, 1)
+					}
+					dirty = true
+				case *sdl.MouseWheelEvent:
+					x, y, _ := sdl.GetMouseState()
+					w.Zoom(e.Y > 0, x, y)
+					dirty = true
+				case *sdl.MouseButtonEvent:
+					// The left button edits cells, and the others drag the board around.
+					if e.Button == sdl.BUTTON_LEFT {
+						mouse.handle(w, event)
+					} else {
+						panning = e.Type == sdl.MOUSEBUTTONDOWN
 					}
+				case *sdl.MouseMotionEvent:
+					if panning {
+						w.Drag(e.XRel, e.YRel)
+						dirty = true
+					} else {
+						mouse.handle(w, event)
+					}
+				case *sdl.WindowEvent:
+					dirty = true
 				}
 			}
 			if dirty {
+				if status.shown {
+					w.SetHUD(status.lines(w))
+				} else {
+					w.SetHUD(nil)
+				}
 				w.RenderFrame()
 				dirty = false
 			}
@@ -52,24 +118,35 @@ sdl:
 			}
 			switch e := event.(type) {
 			case gol.CellFlipped:
+				w.SetTurn(e.CompletedTurns)
 				w.FlipPixel(e.Cell.X, e.Cell.Y)
 			case gol.CellsFlipped:
+				w.SetTurn(e.CompletedTurns)
 				for _, cell := range e.Cells {
 					w.FlipPixel(cell.X, cell.Y) 
 				}
 			case gol.TurnComplete:
+				// Cells age and cool even on turns where nothing flips.
+				w.SetTurn(e.CompletedTurns)
+				status.turn = e.CompletedTurns
 				dirty = true
 			case gol.AliveCellsCount:
-				fmt.Printf("Completed Turns %-8v %-20v Avg%+5v turns/sec\n", event.GetCompletedTurns(), event, avgTurns.Get(event.GetCompletedTurns()))
+				status.rate = avgTurns.Get(event.GetCompletedTurns())
+				fmt.Printf("Completed Turns %-8v %-20v Avg%+5v turns/sec\n", event.GetCompletedTurns(), event, status.rate)
+				dirty = true
 			case gol.FinalTurnComplete:
 				fmt.Printf("Completed Turns %-8v %v\n", event.GetCompletedTurns(), event)
-			case gol.ImageOutputComplete:
+			case gol.ImageOutputComplete, gol.CheckpointComplete, gol.CycleDetected:
+				fmt.Printf("Completed Turns %-8v %v\n", event.GetCompletedTurns(), event)
+			case gol.ErrorEvent, gol.WorkerWarning:
 				fmt.Printf("Completed Turns %-8v %v\n", event.GetCompletedTurns(), event)
 			case gol.StateChange:
 				fmt.Printf("Completed Turns %-8v %v\n", event.GetCompletedTurns(), event)
 				if e.NewState == gol.Quitting {
 					break sdl
 				}
+				status.state = e.NewState
+				dirty = true
 			}
 		}
 	}
@@ -83,7 +160,9 @@ func RunHeadless(events <-chan gol.Event) {
 			fmt.Printf("Completed Turns %-8v %-20v Avg%+5v turns/sec\n", event.GetCompletedTurns(), event, avgTurns.Get(event.GetCompletedTurns()))
 		case gol.FinalTurnComplete:
 			fmt.Printf("Completed Turns %-8v %v\n", event.GetCompletedTurns(), "Final Turn Complete")
-		case gol.ImageOutputComplete:
+		case gol.ImageOutputComplete, gol.CheckpointComplete, gol.CycleDetected:
+			fmt.Printf("Completed Turns %-8v %v\n", event.GetCompletedTurns(), event)
+		case gol.ErrorEvent, gol.WorkerWarning:
 			fmt.Printf("Completed Turns %-8v %v\n", event.GetCompletedTurns(), event)
 		case gol.StateChange:
 			fmt.Printf("Completed Turns %-8v %v\n", event.GetCompletedTurns(), event)
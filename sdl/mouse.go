@@ -0,0 +1,86 @@
+package sdl
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// painter turns the mouse into cell edits. Clicking a cell toggles it, and dragging
+// on from there paints every cell the pointer passes over the same way.
+type painter struct {
+	edits    chan<- gol.CellEdit
+	painting bool
+	alive    bool
+	last     util.Cell
+}
+
+func (pt *painter) handle(w *Window, event sdl.Event) {
+	switch e := event.(type) {
+	case *sdl.MouseButtonEvent:
+		if e.Button != sdl.BUTTON_LEFT {
+			return
+		}
+		cell, ok := w.CellAt(e.X, e.Y)
+		if e.Type == sdl.MOUSEBUTTONUP || !ok {
+			pt.painting = false
+			return
+		}
+		pt.painting = true
+		pt.alive = !w.IsAlive(cell.X, cell.Y)
+		pt.last = cell
+		pt.send(cell)
+	case *sdl.MouseMotionEvent:
+		cell, ok := w.CellAt(e.X, e.Y)
+		if !pt.painting || !ok || cell == pt.last {
+			return
+		}
+		// A quick drag skips cells between motion events, so the line between them is painted.
+		for _, c := range line(pt.last, cell)[1:] {
+			pt.send(c)
+		}
+		pt.last = cell
+	}
+}
+
+// send passes an edit on without waiting, as the distributor may itself be waiting for the window to take events.
+// A dropped edit is never drawn, since cells are only drawn once the distributor sends them back as flipped.
+func (pt *painter) send(cell util.Cell) {
+	select {
+	case pt.edits <- gol.CellEdit{Cell: cell, Alive: pt.alive}:
+	default:
+	}
+}
+
+// line returns the cells from a to b inclusive, using Bresenham's algorithm.
+func line(a, b util.Cell) []util.Cell {
+	dx, dy := abs(b.X-a.X), -abs(b.Y-a.Y)
+	sx, sy := 1, 1
+	if a.X > b.X {
+		sx = -1
+	}
+	if a.Y > b.Y {
+		sy = -1
+	}
+	cells := []util.Cell{a}
+	for err := dx + dy; a != b; {
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			a.X += sx
+		}
+		if e2 <= dx {
+			err += dx
+			a.Y += sy
+		}
+		cells = append(cells, a)
+	}
+	return cells
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
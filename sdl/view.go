@@ -0,0 +1,185 @@
+package sdl
+
+import (
+	"math"
+
+	"github.com/veandco/go-sdl2/sdl"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// maxZoom is the most window pixels a cell can be drawn across.
+const maxZoom = 64
+
+// view is the part of the board shown in the window. When fitting, the whole board is scaled to fill
+// as much of the window as it can; otherwise each cell is zoom pixels square and x, y is the cell
+// shown in the top left corner.
+type view struct {
+	fit  bool
+	zoom int32
+	x, y int32
+}
+
+// layout returns the cells of a width by height board to show and where in a window of the given size to draw them.
+// It keeps the view on the board, and centres a board that is smaller than the window.
+func (v *view) layout(width, height, windowWidth, windowHeight int32) (src, dst sdl.Rect) {
+	if v.fit {
+		// A board that fits is scaled by a whole number, so that every cell is the same size.
+		scale := float64(windowWidth) / float64(width)
+		if s := float64(windowHeight) / float64(height); s < scale {
+			scale = s
+		}
+		if scale >= 1 {
+			scale = float64(int(scale))
+		}
+		dst.W, dst.H = int32(float64(width)*scale), int32(float64(height)*scale)
+		dst.X, dst.Y = (windowWidth-dst.W)/2, (windowHeight-dst.H)/2
+		return sdl.Rect{W: width, H: height}, dst
+	}
+	src.X, src.W, dst.X = axis(&v.x, width, windowWidth, v.zoom)
+	src.Y, src.H, dst.Y = axis(&v.y, height, windowHeight, v.zoom)
+	dst.W, dst.H = src.W*v.zoom, src.H*v.zoom
+	return src, dst
+}
+
+// axis lays out one axis of a zoomed view, returning the first cell shown, how many are shown and where the first is drawn.
+// The last cell shown may only partly fit in the window.
+func axis(first *int32, cells, window, zoom int32) (int32, int32, int32) {
+	shown := (window + zoom - 1) / zoom
+	if shown >= cells {
+		*first = 0
+		return 0, cells, (window - cells*zoom) / 2
+	}
+	if *first > cells-shown {
+		*first = cells - shown
+	}
+	if *first < 0 {
+		*first = 0
+	}
+	return *first, shown, 0
+}
+
+// cellAt returns the cell of a width by height board under the pixel x, y of the window, if there is one.
+func (v *view) cellAt(width, height, windowWidth, windowHeight, x, y int32) (util.Cell, bool) {
+	src, dst := v.layout(width, height, windowWidth, windowHeight)
+	if x < dst.X || y < dst.Y || x >= dst.X+dst.W || y >= dst.Y+dst.H {
+		return util.Cell{}, false
+	}
+	cell := util.Cell{
+		X: int(src.X + (x-dst.X)*src.W/dst.W),
+		Y: int(src.Y + (y-dst.Y)*src.H/dst.H),
+	}
+	if cell.X >= int(width) || cell.Y >= int(height) {
+		return util.Cell{}, false
+	}
+	return cell, true
+}
+
+// zoomAbout doubles or halves the size of the cells, keeping the cell under the pixel x, y of the window where it is.
+// Zooming out of a single pixel per cell fits the board to the window, and zooming in from there
+// starts from the size the board was fitted at.
+func (v *view) zoomAbout(in bool, width, height, windowWidth, windowHeight, x, y int32) {
+	if !in && v.fit {
+		return
+	}
+	src, dst := v.layout(width, height, windowWidth, windowHeight)
+	scale := float64(dst.W) / float64(src.W)
+	cellX := float64(src.X) + float64(x-dst.X)/scale
+	cellY := float64(src.Y) + float64(y-dst.Y)/scale
+
+	zoom := v.zoom
+	if v.fit {
+		zoom = int32(scale)
+	}
+	switch {
+	case in && zoom < 1:
+		zoom = 1
+	case in:
+		zoom *= 2
+	case zoom <= 1:
+		v.fit = true
+		return
+	default:
+		zoom /= 2
+	}
+	if zoom > maxZoom {
+		zoom = maxZoom
+	}
+	// The view starts where the same cell is drawn under x, y.
+	*v = view{zoom: zoom, x: int32(math.Floor(cellX)) - x/zoom, y: int32(math.Floor(cellY)) - y/zoom}
+}
+
+// outputSize returns the size of the window in renderer pixels.
+func (w *Window) outputSize() (int32, int32) {
+	windowWidth, windowHeight, err := w.renderer.GetOutputSize()
+	util.Check(err)
+	return windowWidth, windowHeight
+}
+
+// layout lays out the view in the window as it is now.
+func (w *Window) layout() (src, dst sdl.Rect) {
+	windowWidth, windowHeight := w.outputSize()
+	return w.view.layout(w.Width, w.Height, windowWidth, windowHeight)
+}
+
+// toPixels converts a point given by a window event into renderer pixels, which differ on high density displays.
+func (w *Window) toPixels(x, y int32) (int32, int32) {
+	windowWidth, windowHeight := w.window.GetSize()
+	outputWidth, outputHeight := w.outputSize()
+	if windowWidth == 0 || windowHeight == 0 {
+		return x, y
+	}
+	return x * outputWidth / windowWidth, y * outputHeight / windowHeight
+}
+
+// CellAt returns the cell under a point given by a window event, if there is one.
+func (w *Window) CellAt(x, y int32) (util.Cell, bool) {
+	x, y = w.toPixels(x, y)
+	windowWidth, windowHeight := w.outputSize()
+	return w.view.cellAt(w.Width, w.Height, windowWidth, windowHeight, x, y)
+}
+
+// Zoom zooms about the point x, y given by a window event.
+func (w *Window) Zoom(in bool, x, y int32) {
+	x, y = w.toPixels(x, y)
+	windowWidth, windowHeight := w.outputSize()
+	w.view.zoomAbout(in, w.Width, w.Height, windowWidth, windowHeight, x, y)
+}
+
+// ZoomCentre zooms about the centre of the window.
+func (w *Window) ZoomCentre(in bool) {
+	windowWidth, windowHeight := w.window.GetSize()
+	w.Zoom(in, windowWidth/2, windowHeight/2)
+}
+
+// Fit toggles between fitting the board to the window and the zoomed view.
+func (w *Window) Fit() {
+	w.view.fit = !w.view.fit
+}
+
+// Pan moves the view an eighth of the way across the window in each direction given, by -1 or 1.
+func (w *Window) Pan(dx, dy int32) {
+	src, _ := w.layout()
+	w.view.x += dx * max32(src.W/8, 1)
+	w.view.y += dy * max32(src.H/8, 1)
+}
+
+// Drag moves the board with the pointer, which moved x, y as given by a window event.
+func (w *Window) Drag(x, y int32) {
+	if w.view.fit {
+		return
+	}
+	x, y = w.toPixels(x, y)
+	w.panX += x
+	w.panY += y
+	w.view.x -= w.panX / w.view.zoom
+	w.view.y -= w.panY / w.view.zoom
+	w.panX %= w.view.zoom
+	w.panY %= w.view.zoom
+}
+
+func max32(a, b int32) int32 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,134 @@
+package sdl
+
+import (
+	"testing"
+
+	"github.com/veandco/go-sdl2/sdl"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// TestLayout tests fitting boards to windows, and clamping and centring zoomed views.
+func TestLayout(t *testing.T) {
+	tests := []struct {
+		name                      string
+		v                         view
+		width, height             int32
+		windowWidth, windowHeight int32
+		src, dst                  sdl.Rect
+		x, y                      int32 // Where a zoomed view is left starting.
+	}{
+		{"fit wide", view{fit: true}, 64, 32, 512, 512, sdl.Rect{W: 64, H: 32}, sdl.Rect{X: 0, Y: 128, W: 512, H: 256}, 0, 0},
+		{"fit tall", view{fit: true}, 32, 64, 512, 512, sdl.Rect{W: 32, H: 64}, sdl.Rect{X: 128, Y: 0, W: 256, H: 512}, 0, 0},
+		{"fit whole cells", view{fit: true}, 100, 50, 512, 300, sdl.Rect{W: 100, H: 50}, sdl.Rect{X: 6, Y: 25, W: 500, H: 250}, 0, 0},
+		{"fit shrunk", view{fit: true}, 1024, 512, 512, 512, sdl.Rect{W: 1024, H: 512}, sdl.Rect{X: 0, Y: 128, W: 512, H: 256}, 0, 0},
+		{"zoomed", view{zoom: 4, x: 10, y: 20}, 64, 64, 100, 100, sdl.Rect{X: 10, Y: 20, W: 25, H: 25}, sdl.Rect{W: 100, H: 100}, 10, 20},
+		{"clamped past the end", view{zoom: 4, x: 60, y: 50}, 64, 64, 100, 100, sdl.Rect{X: 39, Y: 39, W: 25, H: 25}, sdl.Rect{W: 100, H: 100}, 39, 39},
+		{"clamped before the start", view{zoom: 4, x: -5, y: -1}, 64, 64, 100, 100, sdl.Rect{W: 25, H: 25}, sdl.Rect{W: 100, H: 100}, 0, 0},
+		{"partly shown cell", view{zoom: 8, x: 0, y: 0}, 64, 64, 100, 100, sdl.Rect{W: 13, H: 13}, sdl.Rect{W: 104, H: 104}, 0, 0},
+		{"centred", view{zoom: 4, x: 3, y: 3}, 16, 8, 100, 100, sdl.Rect{W: 16, H: 8}, sdl.Rect{X: 18, Y: 34, W: 64, H: 32}, 0, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v := test.v
+			src, dst := v.layout(test.width, test.height, test.windowWidth, test.windowHeight)
+			if src != test.src || dst != test.dst {
+				t.Errorf("Expected %+v drawn at %+v, not %+v at %+v", test.src, test.dst, src, dst)
+			}
+			if v.x != test.x || v.y != test.y {
+				t.Errorf("Expected the view to start at %v,%v, not %v,%v", test.x, test.y, v.x, v.y)
+			}
+		})
+	}
+}
+
+// TestZoom tests that zooming in and out about a point keeps the same cell under it.
+// Each zoom leaves the board larger than the window, since a board that fits along an axis is centred.
+func TestZoom(t *testing.T) {
+	tests := []struct {
+		name                      string
+		v                         view
+		width, height             int32
+		windowWidth, windowHeight int32
+		x, y                      int32
+		zooms                     []int32 // The zoom after each zoom in, or out when negative.
+	}{
+		{"from fit", view{fit: true}, 64, 64, 512, 512, 100, 204, []int32{16, 32, 64, 64, -32, -16, -8}},
+		{"from a wide fit", view{fit: true}, 96, 64, 512, 512, 300, 200, []int32{10, 20, -10}},
+		{"from a shrunk fit", view{fit: true}, 1024, 1024, 512, 512, 257, 129, []int32{1, 2, 4, -2, -1}},
+		{"near an edge", view{zoom: 2, x: 0, y: 0}, 256, 256, 128, 128, 3, 125, []int32{4, 8, -4}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v := test.v
+			cell, ok := v.cellAt(test.width, test.height, test.windowWidth, test.windowHeight, test.x, test.y)
+			if !ok {
+				t.Fatalf("%v,%v should be over a cell", test.x, test.y)
+			}
+			for _, zoom := range test.zooms {
+				v.zoomAbout(zoom > 0, test.width, test.height, test.windowWidth, test.windowHeight, test.x, test.y)
+				if zoom < 0 {
+					zoom = -zoom
+				}
+				if v.fit || v.zoom != zoom {
+					t.Fatalf("Expected a zoom of %v, not %+v", zoom, v)
+				}
+				after, ok := v.cellAt(test.width, test.height, test.windowWidth, test.windowHeight, test.x, test.y)
+				if !ok || after != cell {
+					t.Fatalf("Zooming to %v should keep %v under %v,%v, not %v", zoom, cell, test.x, test.y, after)
+				}
+			}
+		})
+	}
+
+	// Zooming out of one pixel a cell fits the board again, and zooming out of a fit does nothing.
+	v := view{zoom: 1}
+	v.zoomAbout(false, 64, 64, 512, 512, 0, 0)
+	if !v.fit {
+		t.Errorf("Zooming out of one pixel a cell should fit the board, not leave %+v", v)
+	}
+	v.zoomAbout(false, 64, 64, 512, 512, 0, 0)
+	if !v.fit {
+		t.Errorf("Zooming out of a fit should leave it fitted, not %+v", v)
+	}
+}
+
+// TestCellAt tests that the centre of each cell drawn maps back to it, and that no cell is under the margins.
+func TestCellAt(t *testing.T) {
+	tests := []struct {
+		name                      string
+		v                         view
+		width, height             int32
+		windowWidth, windowHeight int32
+	}{
+		{"fit", view{fit: true}, 64, 32, 512, 512},
+		{"fit whole cells", view{fit: true}, 100, 50, 512, 300},
+		{"zoomed", view{zoom: 4, x: 10, y: 20}, 64, 64, 100, 100},
+		{"partly shown cell", view{zoom: 8, x: 3, y: 5}, 64, 64, 100, 100},
+		{"centred", view{zoom: 4}, 16, 8, 100, 100},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v := test.v
+			src, dst := v.layout(test.width, test.height, test.windowWidth, test.windowHeight)
+			size := dst.W / src.W
+			for y := src.Y; y < src.Y+src.H; y++ {
+				for x := src.X; x < src.X+src.W; x++ {
+					pixelX, pixelY := dst.X+(x-src.X)*size+size/2, dst.Y+(y-src.Y)*size+size/2
+					if pixelX >= test.windowWidth || pixelY >= test.windowHeight {
+						continue
+					}
+					cell, ok := v.cellAt(test.width, test.height, test.windowWidth, test.windowHeight, pixelX, pixelY)
+					expected := util.Cell{X: int(x), Y: int(y)}
+					if !ok || cell != expected {
+						t.Fatalf("%v,%v should be over %v, not %v", pixelX, pixelY, expected, cell)
+					}
+				}
+			}
+			for _, pixel := range [][2]int32{{dst.X - 1, dst.Y}, {dst.X, dst.Y - 1}, {dst.X + dst.W, dst.Y}, {dst.X, dst.Y + dst.H}} {
+				if cell, ok := v.cellAt(test.width, test.height, test.windowWidth, test.windowHeight, pixel[0], pixel[1]); ok {
+					t.Errorf("%v,%v is outside the board, but is over %v", pixel[0], pixel[1], cell)
+				}
+			}
+		})
+	}
+}
@@ -14,33 +14,69 @@ type Window struct {
 	renderer      *sdl.Renderer
 	texture       *sdl.Texture
 	pixels        []byte
+	view          view
+	panX, panY    int32 // Pixels dragged that do not yet add up to a whole cell.
+
+	// Each cell remembers whether it is alive and the turn it last flipped, which the pixels are coloured from.
+	alive      []bool
+	changed    []int
+	turn       int
+	population int
+	mode       colourMode
+	hud        []string
 }
 
 func filterEvent(e sdl.Event, userdata interface{}) bool {
-	return e.GetType() == sdl.KEYDOWN || e.GetType() == sdl.QUIT
+	switch e.GetType() {
+	case sdl.KEYDOWN, sdl.QUIT, sdl.MOUSEBUTTONDOWN, sdl.MOUSEBUTTONUP, sdl.MOUSEMOTION, sdl.MOUSEWHEEL, sdl.WINDOWEVENT:
+		return true
+	}
+	return false
 }
 
+// The window opens with each cell a whole number of pixels across, making it about defaultWindowSize
+// pixels across unless the board is larger, and never more than maxWindowSize.
+const (
+	defaultWindowSize = 512
+	maxWindowSize     = 1024
+)
+
+// NewWindow opens a resizable window onto a width by height board, which it starts off fitting.
 func NewWindow(width, height int32) *Window {
 	err := sdl.Init(sdl.INIT_EVERYTHING)
 	util.Check(err)
-	window, err := sdl.CreateWindow("GOL GUI", sdl.WINDOWPOS_CENTERED, sdl.WINDOWPOS_CENTERED, width, height, sdl.WINDOW_SHOWN)
+	larger := width
+	if height > larger {
+		larger = height
+	}
+	scale := int32(defaultWindowSize) / larger
+	if scale < 1 {
+		scale = 1
+	}
+	windowWidth, windowHeight := width*scale, height*scale
+	if larger*scale > maxWindowSize {
+		windowWidth, windowHeight = windowWidth*maxWindowSize/(larger*scale), windowHeight*maxWindowSize/(larger*scale)
+	}
+	window, err := sdl.CreateWindow("GOL GUI", sdl.WINDOWPOS_CENTERED, sdl.WINDOWPOS_CENTERED, windowWidth, windowHeight, sdl.WINDOW_SHOWN|sdl.WINDOW_RESIZABLE)
 	util.Check(err)
 	renderer, err := sdl.CreateRenderer(window, -1, sdl.WINDOW_SHOWN)
 	util.Check(err)
-	sdl.SetHint(sdl.HINT_RENDER_SCALE_QUALITY, "linear")
-	err = renderer.SetLogicalSize(width, height)
-	util.Check(err)
+	// Zoomed cells are drawn as sharp squares.
+	sdl.SetHint(sdl.HINT_RENDER_SCALE_QUALITY, "nearest")
 	texture, err := renderer.CreateTexture(sdl.PIXELFORMAT_ARGB8888, sdl.TEXTUREACCESS_STATIC, width, height)
 	util.Check(err)
 
 	sdl.SetEventFilterFunc(filterEvent, nil)
 	return &Window{
-		width,
-		height,
-		window,
-		renderer,
-		texture,
-		make([]byte, width*height*4),
+		Width:    width,
+		Height:   height,
+		window:   window,
+		renderer: renderer,
+		texture:  texture,
+		pixels:   make([]byte, width*height*4),
+		view:     view{fit: true, zoom: scale},
+		alive:    make([]bool, width*height),
+		changed:  make([]int, width*height),
 	}
 }
 
@@ -55,25 +91,35 @@ func (w *Window) Destroy() {
 }
 
 func (w *Window) RenderFrame() {
+	w.paint()
 	err := w.texture.Update(nil, unsafe.Pointer(&w.pixels[0]), int(w.Width*4))
 	util.Check(err)
 	err = w.renderer.Clear()
 	util.Check(err)
-	err = w.renderer.Copy(w.texture, nil, nil)
+	src, dst := w.layout()
+	err = w.renderer.Copy(w.texture, &src, &dst)
 	util.Check(err)
+	w.drawHUD()
 	w.renderer.Present()
 }
 
+func (w *Window) SetTitle(title string) {
+	w.window.SetTitle(title)
+}
+
 func (w *Window) PollEvent() sdl.Event {
 	return sdl.PollEvent()
 }
 
 func (w *Window) SetPixel(x, y int) {
-	width := int(w.Width)
-	w.pixels[4*(y*width+x)+0] = 0xFF
-	w.pixels[4*(y*width+x)+1] = 0xFF
-	w.pixels[4*(y*width+x)+2] = 0xFF
-	w.pixels[4*(y*width+x)+3] = 0xFF
+	if !w.IsAlive(x, y) {
+		w.FlipPixel(x, y)
+	}
+}
+
+// SetTurn sets the turn that the cells flipped from now on change at.
+func (w *Window) SetTurn(turn int) {
+	w.turn = turn
 }
 
 func (w *Window) FlipPixel(x, y int) {
@@ -81,25 +127,49 @@ func (w *Window) FlipPixel(x, y int) {
 		panic(fmt.Sprintf("CellFlipped event at (%d, %d) is outside the bounds of the window.", x, y))
 	}
 
-	width := int(w.Width)
-	w.pixels[4*(y*width+x)+0] = ^w.pixels[4*(y*width+x)+0]
-	w.pixels[4*(y*width+x)+1] = ^w.pixels[4*(y*width+x)+1]
-	w.pixels[4*(y*width+x)+2] = ^w.pixels[4*(y*width+x)+2]
-	w.pixels[4*(y*width+x)+3] = ^w.pixels[4*(y*width+x)+3]
+	i := y*int(w.Width) + x
+	w.alive[i] = !w.alive[i]
+	w.changed[i] = w.turn
+	if w.alive[i] {
+		w.population++
+	} else {
+		w.population--
+	}
 }
 
-func (w *Window) CountPixels() int {
-	count := 0
-	for i := 0; i < int(w.Width) * int(w.Height) * 4; i += 4 {
-		if w.pixels[i] == 0xFF {
-			count++
-		}
+// IsAlive reports whether a cell is alive.
+func (w *Window) IsAlive(x, y int) bool {
+	return w.alive[y*int(w.Width)+x]
+}
+
+// CycleColours switches to the next way of colouring the cells.
+func (w *Window) CycleColours() {
+	w.mode = (w.mode + 1) % colourModes
+}
+
+// paint colours the pixels from the state of each cell.
+func (w *Window) paint() {
+	for i, alive := range w.alive {
+		c := w.mode.colourOf(alive, w.turn-w.changed[i])
+		// ARGB8888 is stored little endian, as B, G, R, A.
+		w.pixels[4*i+0] = c.b
+		w.pixels[4*i+1] = c.g
+		w.pixels[4*i+2] = c.r
+		w.pixels[4*i+3] = 0xFF
 	}
-	return count
+}
+
+func (w *Window) CountPixels() int {
+	return w.population
 }
 
 func (w *Window) ClearPixels() {
 	for i := range w.pixels {
 		w.pixels[i] = 0
 	}
+	for i := range w.alive {
+		w.alive[i] = false
+		w.changed[i] = 0
+	}
+	w.turn, w.population = 0, 0
 }
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+)
+
+// main starts a worker server that computes strips of turns for a broker, or as part of a cluster given to -workers.
+func main() {
+	port := flag.String(
+		"port",
+		"8040",
+		"Specify the port to listen on. Defaults to 8040.")
+
+	flag.Parse()
+
+	listener, err := net.Listen("tcp", ":"+*port)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	fmt.Println("Server listening on", listener.Addr())
+	if err = gol.ServeWorker(listener); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
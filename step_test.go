@@ -0,0 +1,82 @@
+package main
+
+import (
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// TestStep tests stepping forwards with 'n' and back with 'b' while paused.
+func TestStep(t *testing.T) {
+	for _, engine := range []string{"parallel", "hashlife"} {
+		t.Run(engine, func(t *testing.T) {
+			testStep(t, gol.Params{Turns: 100000, Threads: 4, ImageWidth: 64, ImageHeight: 64, Engine: engine, OutDir: t.TempDir()})
+		})
+	}
+	t.Run("cluster", func(t *testing.T) {
+		testStep(t, gol.Params{Turns: 100000, ImageWidth: 64, ImageHeight: 64, Workers: startWorkers(t, 2), OutDir: t.TempDir()})
+	})
+}
+
+func testStep(t *testing.T, p gol.Params) {
+	keyPresses := make(chan rune, 10)
+	events := make(chan gol.Event, 1000)
+	keyPresses <- 'p'
+	go gol.Run(p, events, keyPresses)
+
+	// The world is followed from the flipped cells, as the SDL window does.
+	world := make(map[util.Cell]bool)
+	flip := func(cell util.Cell) {
+		world[cell] = !world[cell]
+	}
+	cells := func() []util.Cell {
+		var alive []util.Cell
+		for cell, isAlive := range world {
+			if isAlive {
+				alive = append(alive, cell)
+			}
+		}
+		return alive
+	}
+
+	script := []rune{'n', 'n', 'n', 'b', 'b', 'n'}
+	offsets := []int{1, 2, 3, 2, 1, 2}
+	pausedAt := -1
+	for event := range events {
+		switch e := event.(type) {
+		case gol.CellFlipped:
+			flip(e.Cell)
+		case gol.CellsFlipped:
+			for _, cell := range e.Cells {
+				flip(cell)
+			}
+		case gol.StateChange:
+			if e.NewState == gol.Paused {
+				pausedAt = e.CompletedTurns
+				keyPresses <- script[0]
+			}
+		case gol.TurnComplete:
+			// The turn that was running when 'p' was pressed may complete after the pause.
+			if pausedAt < 0 || e.CompletedTurns == pausedAt {
+				continue
+			}
+			if len(script) == 0 {
+				t.Fatalf("No more turns should complete after quitting, but %v did", e.CompletedTurns)
+			}
+			expected := pausedAt + offsets[0]
+			assert(t, e.CompletedTurns == expected, "After %q the completed turns should be %v, not %v", script[0], expected, e.CompletedTurns)
+			reference := p
+			reference.Turns = expected
+			assertEqualBoard(t, cells(), referenceRun(readAliveCells("images/64x64.pgm", 64, 64), reference), reference)
+			if script, offsets = script[1:], offsets[1:]; len(script) > 0 {
+				keyPresses <- script[0]
+			} else {
+				keyPresses <- 'q'
+			}
+		case gol.FinalTurnComplete:
+			assert(t, e.CompletedTurns == pausedAt+2, "The run should finish at turn %v, not %v", pausedAt+2, e.CompletedTurns)
+		}
+	}
+	assert(t, len(script) == 0, "The run stopped with %q still to press", script)
+}
@@ -0,0 +1,86 @@
+package stubs
+
+import "uk.ac.bris.cs/gameoflife/util"
+
+// Methods served by the broker to a controller.
+var (
+	BrokerStart    = "Broker.Start"
+	BrokerStep     = "Broker.Step"
+	BrokerStop     = "Broker.Stop"
+	BrokerShutdown = "Broker.Shutdown"
+)
+
+// Methods served by a worker server to the broker, or to a controller and the other workers of a cluster.
+var (
+	WorkerTurn     = "Worker.Turn"
+	WorkerInit     = "Worker.Init"
+	WorkerStep     = "Worker.Step"
+	WorkerHalo     = "Worker.Halo"
+	WorkerStop     = "Worker.Stop"
+	WorkerShutdown = "Worker.Shutdown"
+)
+
+// Empty is the request or response of a method that takes or returns nothing.
+type Empty struct{}
+
+// World is a world packed 64 cells to a word, as in the gol package.
+type World struct {
+	Width, Height int
+	Rows          [][]uint64
+}
+
+// StartRequest hands the initial world to the broker.
+type StartRequest struct {
+	World    World
+	Rule     string
+	Topology string
+}
+
+// StepRequest asks the broker for at least one and at most MaxTurns turns.
+type StepRequest struct {
+	MaxTurns int
+}
+
+// StepResponse reports the turns the broker completed and the cells that flipped over them.
+type StepResponse struct {
+	Turns   int
+	Flipped []util.Cell
+	Alive   int
+}
+
+// TurnRequest asks a worker for the next state of rows [StartY, EndY) of the world.
+// Rows holds the row above the strip, the strip itself and the row below it.
+// Left and Right hold the edge columns of the whole world, which only a cross-surface needs.
+type TurnRequest struct {
+	Width, Height int
+	StartY, EndY  int
+	Rule          string
+	Topology      string
+	Rows          [][]uint64
+	Left, Right   []bool
+}
+
+// TurnResponse holds the next state of the requested rows.
+type TurnResponse struct {
+	Rows  [][]uint64
+	Alive int
+}
+
+// InitRequest hands a worker of a cluster its strip, rows [StartY, EndY) of the world.
+// Above and Below are the addresses of the workers holding the neighbouring strips,
+// or empty if there is no neighbour across that edge or the worker is its own neighbour.
+type InitRequest struct {
+	Width, Height int
+	StartY, EndY  int
+	Rule          string
+	Topology      string
+	Rows          [][]uint64
+	Above, Below  string
+}
+
+// HaloRequest passes a boundary row to a neighbouring worker.
+// Below is set if the row lies directly below the receiver's strip, rather than directly above it.
+type HaloRequest struct {
+	Below bool
+	Row   []uint64
+}
@@ -0,0 +1,95 @@
+package term
+
+import (
+	"io"
+	"os"
+	"unicode/utf8"
+)
+
+// The arrow keys are read as these runes, which no key press uses.
+const (
+	keyUp rune = -1 - iota
+	keyDown
+	keyRight
+	keyLeft
+)
+
+// arrows maps the final byte of the escape sequence of each arrow key to its rune.
+var arrows = map[byte]rune{'A': keyUp, 'B': keyDown, 'C': keyRight, 'D': keyLeft}
+
+// readKeys sends the keys read from in on keys until in ends or done is closed.
+func readKeys(in io.Reader, keys chan<- rune, done <-chan struct{}) {
+	buf := make([]byte, 64)
+	for {
+		n, err := in.Read(buf)
+		for _, key := range decodeKeys(buf[:n]) {
+			select {
+			case keys <- key:
+			case <-done:
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// decodeKeys splits bytes read from a terminal into keys. A terminal sends each arrow key as one escape sequence,
+// which arrives in a single read.
+func decodeKeys(b []byte) []rune {
+	var keys []rune
+	for len(b) > 0 {
+		if len(b) >= 3 && b[0] == 0x1b && (b[1] == '[' || b[1] == 'O') {
+			if key, ok := arrows[b[2]]; ok {
+				keys = append(keys, key)
+				b = b[3:]
+				continue
+			}
+		}
+		r, n := utf8.DecodeRune(b)
+		keys = append(keys, r)
+		b = b[n:]
+	}
+	return keys
+}
+
+// ForwardKeys sends the keys pressed on in to the simulation on keyPresses, reading each key as it is pressed
+// if in is a terminal, until the returned function is called to put the terminal back as it was.
+// A process in the background of its terminal does not read keys, so that it is not stopped.
+func ForwardKeys(in io.Reader, keyPresses chan<- rune) func() {
+	restoreTerminal := func() {}
+	if f, ok := in.(*os.File); ok && isTerminal(f.Fd()) {
+		if !foreground(f.Fd()) {
+			return restoreTerminal
+		}
+		if restore, err := makeRaw(f.Fd()); err == nil {
+			restoreTerminal = restore
+		}
+	}
+	keys := make(chan rune, 10)
+	done := make(chan struct{})
+	go readKeys(in, keys, done)
+	go func() {
+		for {
+			select {
+			case key := <-keys:
+				// The arrow keys only scroll the terminal renderer.
+				if key < 0 {
+					continue
+				}
+				select {
+				case keyPresses <- key:
+				case <-done:
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		restoreTerminal()
+	}
+}
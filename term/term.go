@@ -0,0 +1,205 @@
+package term
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// FPS is the most times a second the board is redrawn.
+const FPS = 20
+
+// The terminal is assumed to be defaultColumns by defaultRows when out is not a terminal.
+const (
+	defaultColumns = 80
+	defaultRows    = 24
+)
+
+// screen is the world as drawn in the terminal, with a status line beneath it.
+type screen struct {
+	width, height int
+	world         [][]uint8
+	alive         int
+	x, y          int // The cell in the top left corner of the view.
+	turn          int
+	rate          int
+	state         gol.State
+	message       string
+}
+
+func newScreen(width, height int) *screen {
+	world := make([][]uint8, height)
+	for i := range world {
+		world[i] = make([]uint8, width)
+	}
+	return &screen{width: width, height: height, world: world, state: gol.Executing}
+}
+
+func (s *screen) flip(cell util.Cell) {
+	s.world[cell.Y][cell.X] = ^s.world[cell.Y][cell.X]
+	if s.world[cell.Y][cell.X] == 0xFF {
+		s.alive++
+	} else {
+		s.alive--
+	}
+}
+
+// view returns how many columns and rows of cells fit in a terminal of the given size, keeping the view on the board.
+// Each line shows two rows of cells, and the last line is the status line.
+func (s *screen) view(columns, rows int) (int, int) {
+	width, height := columns, 2*(rows-1)
+	if width > s.width {
+		width = s.width
+	}
+	if height > s.height {
+		height = s.height
+	}
+	if height < 0 {
+		height = 0
+	}
+	s.x = clamp(s.x, 0, s.width-width)
+	s.y = clamp(s.y, 0, s.height-height)
+	return width, height
+}
+
+// scroll moves the view an eighth of the way across the terminal in each direction given, by -1 or 1.
+func (s *screen) scroll(dx, dy, columns, rows int) {
+	width, height := s.view(columns, rows)
+	s.x += dx * clamp(width/8, 1, width)
+	s.y += dy * clamp(height/8, 1, height)
+}
+
+// draw returns the escape codes and characters that redraw the screen in place.
+func (s *screen) draw(columns, rows int) string {
+	width, height := s.view(columns, rows)
+	var frame strings.Builder
+	frame.WriteString("\x1b[H")
+	for _, line := range util.HalfBlocksToStrings(s.world, s.x, s.y, width, height) {
+		frame.WriteString(line)
+		frame.WriteString("\x1b[K\r\n")
+	}
+	status := fmt.Sprintf("Turn %v  Alive %v  %v turns/s  %v", s.turn, s.alive, s.rate, s.state)
+	if width < s.width || height < s.height {
+		status += fmt.Sprintf("  View %v,%v", s.x, s.y)
+	}
+	if s.message != "" {
+		status += "  " + s.message
+	}
+	if runes := []rune(status); len(runes) > columns {
+		status = string(runes[:columns])
+	}
+	frame.WriteString(status)
+	frame.WriteString("\x1b[K\x1b[J")
+	return frame.String()
+}
+
+// terminalSize returns the size of out, or the default size if it is not a terminal.
+func terminalSize(out io.Writer) (int, int) {
+	if f, ok := out.(*os.File); ok {
+		if columns, rows, err := size(f.Fd()); err == nil && columns > 0 && rows > 1 {
+			return columns, rows
+		}
+	}
+	return defaultColumns, defaultRows
+}
+
+// Run draws the world in the terminal out until the simulation quits, reading keys from in.
+// The keys of the simulation are sent on keyPresses and the arrow keys scroll a board larger than the terminal.
+// The events printed by the other modes are shown on the status line and printed beneath the board at the end.
+func Run(p gol.Params, events <-chan gol.Event, keyPresses chan<- rune, in io.Reader, out io.Writer) {
+	if f, ok := in.(*os.File); ok {
+		if restore, err := makeRaw(f.Fd()); err == nil {
+			defer restore()
+		}
+	}
+	keys := make(chan rune, 10)
+	done := make(chan struct{})
+	defer close(done)
+	go readKeys(in, keys, done)
+
+	s := newScreen(p.ImageWidth, p.ImageHeight)
+	var log []string
+	avgTurns := util.NewAvgTurns()
+	refreshTicker := time.NewTicker(time.Second / time.Duration(FPS))
+	defer refreshTicker.Stop()
+	// The cursor is hidden while drawing, and the board is left on screen at the end.
+	fmt.Fprint(out, "\x1b[?25l\x1b[2J")
+	dirty := true
+
+term:
+	for {
+		select {
+		case <-refreshTicker.C:
+			if dirty {
+				fmt.Fprint(out, s.draw(terminalSize(out)))
+				dirty = false
+			}
+
+		case key := <-keys:
+			columns, rows := terminalSize(out)
+			switch key {
+			case keyUp:
+				s.scroll(0, -1, columns, rows)
+			case keyDown:
+				s.scroll(0, 1, columns, rows)
+			case keyLeft:
+				s.scroll(-1, 0, columns, rows)
+			case keyRight:
+				s.scroll(1, 0, columns, rows)
+			default:
+				keyPresses <- key
+			}
+			dirty = true
+
+		case event, ok := <-events:
+			if !ok {
+				break term
+			}
+			switch e := event.(type) {
+			case gol.CellFlipped:
+				s.flip(e.Cell)
+			case gol.CellsFlipped:
+				for _, cell := range e.Cells {
+					s.flip(cell)
+				}
+			case gol.TurnComplete:
+				s.turn = e.CompletedTurns
+				dirty = true
+			case gol.AliveCellsCount:
+				s.rate = avgTurns.Get(event.GetCompletedTurns())
+			case gol.FinalTurnComplete, gol.ImageOutputComplete, gol.CheckpointComplete, gol.CycleDetected,
+				gol.ErrorEvent, gol.WorkerWarning, gol.StateChange:
+				s.message = fmt.Sprint(event)
+				log = append(log, fmt.Sprintf("Completed Turns %-8v %v", event.GetCompletedTurns(), event))
+				if e, ok := event.(gol.StateChange); ok {
+					if e.NewState == gol.Quitting {
+						break term
+					}
+					s.state = e.NewState
+				}
+				dirty = true
+			}
+		}
+	}
+
+	fmt.Fprint(out, s.draw(terminalSize(out)))
+	fmt.Fprint(out, "\x1b[?25h\r\n")
+	for _, line := range log {
+		fmt.Fprintln(out, line)
+	}
+}
+
+func clamp(n, low, high int) int {
+	if n > high {
+		n = high
+	}
+	if n < low {
+		n = low
+	}
+	return n
+}
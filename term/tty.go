@@ -0,0 +1,63 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package term
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+func ioctl(fd, request uintptr, arg unsafe.Pointer) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, request, uintptr(arg))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// makeRaw stops the terminal fd from buffering lines and echoing keys, so each key is read as it is pressed,
+// and returns a function that restores it. Ctrl-C still interrupts the process.
+func makeRaw(fd uintptr) (func(), error) {
+	var old syscall.Termios
+	if err := ioctl(fd, getTermios, unsafe.Pointer(&old)); err != nil {
+		return nil, err
+	}
+	raw := old
+	raw.Lflag &^= syscall.ICANON | syscall.ECHO
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if err := ioctl(fd, setTermios, unsafe.Pointer(&raw)); err != nil {
+		return nil, err
+	}
+	return func() {
+		_ = ioctl(fd, setTermios, unsafe.Pointer(&old))
+	}, nil
+}
+
+// size returns the columns and rows of the terminal fd.
+func size(fd uintptr) (int, int, error) {
+	var ws struct {
+		Row, Col, X, Y uint16
+	}
+	if err := ioctl(fd, syscall.TIOCGWINSZ, unsafe.Pointer(&ws)); err != nil {
+		return 0, 0, err
+	}
+	return int(ws.Col), int(ws.Row), nil
+}
+
+// isTerminal reports whether fd is a terminal.
+func isTerminal(fd uintptr) bool {
+	var termios syscall.Termios
+	return ioctl(fd, getTermios, unsafe.Pointer(&termios)) == nil
+}
+
+// foreground reports whether the process is in the foreground of the terminal fd. A process in the
+// background is stopped when it reads from or changes the terminal.
+func foreground(fd uintptr) bool {
+	var pgrp int32
+	if err := ioctl(fd, syscall.TIOCGPGRP, unsafe.Pointer(&pgrp)); err != nil {
+		return false
+	}
+	return int(pgrp) == syscall.Getpgrp()
+}
@@ -0,0 +1,24 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package term
+
+import "errors"
+
+var errNoTerminal = errors.New("terminal control is not supported on this platform")
+
+func makeRaw(fd uintptr) (func(), error) {
+	return nil, errNoTerminal
+}
+
+func size(fd uintptr) (int, int, error) {
+	return 0, 0, errNoTerminal
+}
+
+func isTerminal(fd uintptr) bool {
+	return false
+}
+
+func foreground(fd uintptr) bool {
+	return false
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/term"
+)
+
+// TestTerm tests drawing a board larger than the terminal, scrolled with the arrow keys and quit with 'q' from stdin.
+func TestTerm(t *testing.T) {
+	blinker := writePattern(t, "blinker.cells", "OOO\n")
+	p := gol.Params{Turns: 100000000, Threads: 4, ImageWidth: 100, ImageHeight: 60, Input: blinker, OffsetX: 90, OffsetY: 50, OutDir: t.TempDir()}
+	keyPresses := make(chan rune, 10)
+	events := make(chan gol.Event, 1000)
+	go gol.Run(p, events, keyPresses)
+
+	// Output that is not a terminal is drawn 80 columns by 24 rows, which shows 80 by 46 cells.
+	in, stdin := io.Pipe()
+	var out bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		term.Run(p, events, keyPresses, in, &out)
+		close(done)
+	}()
+	_, err := io.WriteString(stdin, "\x1b[C\x1b[C\x1b[B\x1b[B\x1b[Bq")
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-done
+
+	frames := strings.Split(out.String(), "\x1b[H")
+	lines := strings.Split(frames[len(frames)-1], "\x1b[K\r\n")
+	assert(t, len(lines) == 24, "The final frame should have 23 lines of cells and a status line, not %v lines", len(lines))
+	if len(lines) != 24 {
+		return
+	}
+	status := lines[23]
+	assert(t, strings.Contains(status, "View 20,14"), "Scrolling to the corner should show the view from 20,14, but the status is %q", status)
+
+	var turn int
+	if _, err := fmt.Sscanf(status, "Turn %d", &turn); err != nil {
+		t.Fatalf("The status %q should start with the turn", status)
+	}
+	// The blinker is at 90,50 on even turns and 91,49 on odd turns, which is 70,36 and 71,35 in the view.
+	cell := func(line, column int) string {
+		return string([]rune(lines[line])[column])
+	}
+	if turn%2 == 0 {
+		drawn := cell(18, 70) + cell(18, 71) + cell(18, 72)
+		assert(t, drawn == "▀▀▀", "The blinker should be drawn as ▀▀▀ on turn %v, not %q", turn, drawn)
+	} else {
+		drawn := cell(17, 71) + cell(18, 71)
+		assert(t, drawn == "▄█", "The blinker should be drawn as ▄ over █ on turn %v, not %q", turn, drawn)
+	}
+	assert(t, strings.Contains(out.String(), "Final Turn Complete"), "The final turn should be printed beneath the board")
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+)
+
+// TestThrottle tests capping the turns per second with -tps and changing the cap with '+', '-' and '0'.
+func TestThrottle(t *testing.T) {
+	t.Run("tps", testThrottleTPS)
+	t.Run("keys", testThrottleKeys)
+}
+
+func testThrottleTPS(t *testing.T) {
+	p := gol.Params{Turns: 10, Threads: 4, ImageWidth: 16, ImageHeight: 16, TPS: 20, OutDir: t.TempDir()}
+	start := time.Now()
+	final := runFinal(p)
+	elapsed := time.Since(start)
+	assert(t, elapsed >= 400*time.Millisecond && elapsed < 2*time.Second, "10 turns at 20 turns per second should take about 0.5s, not %v", elapsed)
+	assertEqualBoard(t, final, referenceRun(readAliveCells("images/16x16.pgm", 16, 16), p), p)
+}
+
+// testThrottleKeys slows an unlimited run down with '-', speeds it up with '+' and lifts the cap with '0'.
+func testThrottleKeys(t *testing.T) {
+	p := gol.Params{Turns: 100000000, Threads: 4, ImageWidth: 64, ImageHeight: 64, OutDir: t.TempDir()}
+	keyPresses := make(chan rune, 10)
+	events := make(chan gol.Event, 1000)
+	go gol.Run(p, events, keyPresses)
+
+	// turnsIn returns how many turns complete over d.
+	turnsIn := func(d time.Duration) int {
+		first, last := -1, -1
+		deadline := time.After(d)
+		for {
+			select {
+			case event := <-events:
+				if e, ok := event.(gol.TurnComplete); ok {
+					if first < 0 {
+						first = e.CompletedTurns
+					}
+					last = e.CompletedTurns
+				}
+			case <-deadline:
+				return last - first
+			}
+		}
+	}
+
+	keyPresses <- '-'
+	keyPresses <- '-'
+	turnsIn(100 * time.Millisecond)
+	slow := turnsIn(time.Second)
+	assert(t, slow >= 20 && slow <= 40, "At 30 turns per second about 30 turns should complete in a second, not %v", slow)
+
+	keyPresses <- '+'
+	turnsIn(100 * time.Millisecond)
+	faster := turnsIn(time.Second)
+	assert(t, faster >= 45 && faster <= 75, "At 60 turns per second about 60 turns should complete in a second, not %v", faster)
+
+	keyPresses <- '0'
+	turnsIn(100 * time.Millisecond)
+	unlimited := turnsIn(time.Second)
+	assert(t, unlimited > 200, "Without a cap far more than 60 turns should complete in a second, not %v", unlimited)
+
+	keyPresses <- 'q'
+	for range events {
+	}
+}
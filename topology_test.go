@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// TestTopology compares every topology on the 16x16 and 64x64 images against a naive reference implementation.
+func TestTopology(t *testing.T) {
+	topologies := []gol.Topology{gol.Torus, gol.Bounded, gol.Cylinder, gol.KleinBottle, gol.CrossSurface}
+	for _, size := range []int{16, 64} {
+		for _, topology := range topologies {
+			p := gol.Params{ImageWidth: size, ImageHeight: size, Turns: 100, Topology: topology}
+			expectedAlive := referenceRun(readAliveCells(fmt.Sprintf("images/%vx%v.pgm", size, size), size, size), p)
+			for _, threads := range []int{1, 3, 8} {
+				p.Threads = threads
+				t.Run(fmt.Sprintf("%vx%v-%v-%d", size, size, topology, threads), func(t *testing.T) {
+					events := make(chan gol.Event)
+					go gol.Run(p, events, nil)
+					var cells []util.Cell
+					for event := range events {
+						switch e := event.(type) {
+						case gol.FinalTurnComplete:
+							cells = e.Alive
+						}
+					}
+					assertEqualBoard(t, cells, expectedAlive, p)
+				})
+			}
+		}
+	}
+}
+
+// referenceRun evolves the given cells under Conway's rules, following each edge of the topology explicitly.
+func referenceRun(alive []util.Cell, p gol.Params) []util.Cell {
+	width, height := p.ImageWidth, p.ImageHeight
+	world := make(map[util.Cell]bool)
+	for _, cell := range alive {
+		world[cell] = true
+	}
+	get := func(x, y int) bool {
+		flipX, flipY := false, false
+		if y < 0 || y >= height {
+			if p.Topology == gol.Bounded || p.Topology == gol.Cylinder {
+				return false
+			}
+			flipX = p.Topology == gol.KleinBottle || p.Topology == gol.CrossSurface
+		}
+		if x < 0 || x >= width {
+			if p.Topology == gol.Bounded {
+				return false
+			}
+			flipY = p.Topology == gol.CrossSurface
+		}
+		if flipX {
+			x = width - 1 - x
+		}
+		x = (x + width) % width
+		if flipY {
+			y = height - 1 - y
+		}
+		y = (y + height) % height
+		return world[util.Cell{X: x, Y: y}]
+	}
+	for turn := 0; turn < p.Turns; turn++ {
+		next := make(map[util.Cell]bool)
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				neighbours := 0
+				for dy := -1; dy <= 1; dy++ {
+					for dx := -1; dx <= 1; dx++ {
+						if (dx != 0 || dy != 0) && get(x+dx, y+dy) {
+							neighbours++
+						}
+					}
+				}
+				if neighbours == 3 || neighbours == 2 && world[util.Cell{X: x, Y: y}] {
+					next[util.Cell{X: x, Y: y}] = true
+				}
+			}
+		}
+		world = next
+	}
+	var cells []util.Cell
+	for cell := range world {
+		cells = append(cells, cell)
+	}
+	return cells
+}
@@ -106,3 +106,31 @@ func squaresToStrings(given, expected [][]uint8, width, height int) []string {
 
 	return output
 }
+
+// HalfBlocksToStrings draws the width by height cells of given from x, y, two rows of cells to each line of
+// characters, using half blocks so that each cell is roughly square in a terminal. Cells past the edge of the
+// world are drawn dead.
+func HalfBlocksToStrings(given [][]uint8, x, y, width, height int) []string {
+	alive := func(i, j int) bool {
+		return i >= 0 && i < len(given) && j >= 0 && j < len(given[i]) && given[i][j] == 0xFF
+	}
+	var output []string
+	for i := y; i < y+height; i += 2 {
+		var line strings.Builder
+		for j := x; j < x+width; j++ {
+			top, bottom := alive(i, j), i+1 < y+height && alive(i+1, j)
+			switch {
+			case top && bottom:
+				line.WriteString("█")
+			case top:
+				line.WriteString("▀")
+			case bottom:
+				line.WriteString("▄")
+			default:
+				line.WriteString(" ")
+			}
+		}
+		output = append(output, line.String())
+	}
+	return output
+}